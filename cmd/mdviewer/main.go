@@ -3,6 +3,9 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/aquele_dinho/mdviewer/internal/pdf"
 	"github.com/aquele_dinho/mdviewer/internal/renderer"
@@ -16,14 +19,43 @@ var version = "dev"
 
 var (
 	// Global flags
-	style            string
-	width            int
-	noMermaid        bool
-	openMermaid      bool
-	exportPDF        string
-	mermaidMode      string
-	mermaidOutDir    string
-	keepMermaidFiles bool
+	style             string
+	width             int
+	noMermaid         bool
+	openMermaid       bool
+	exportPDF         string
+	mermaidMode       string
+	mermaidOutDir     string
+	keepMermaidFiles  bool
+	fetchRemoteImages bool
+	offline           bool
+	remoteTimeout     time.Duration
+	remoteMaxSize     string
+	imageResampling   string
+	noAnimate         bool
+	showComments      bool
+	noFrontmatter     bool
+	mermaidTheme      string
+	toc               bool
+	outline           bool
+	section           string
+	hyperlinks        string
+	pdfPaper          string
+	pdfLandscape      bool
+	pdfMargins        string
+	pdfScale          float64
+	pdfHeader         string
+	pdfFooter         string
+	pdfLabel          string
+	pdfLogo           string
+	pdfPageNumbers    bool
+	pdfTOC            bool
+	pdfTheme          string
+	cssFile           string
+	cssReplace        bool
+	htmlTemplate      string
+	pdfCodeStyle      string
+	pdfLineNumbers    bool
 )
 
 func main() {
@@ -45,6 +77,8 @@ Examples:
   cat file.md | mdviewer                # Read from stdin
   mdviewer file.md --style dark         # Use dark theme
   mdviewer file.md --export-pdf out.pdf # Export to PDF
+  mdviewer runbook.md#deploy-steps      # View a single section
+  mdviewer export book.pdf ch1.md ch2.md # Export several files as one PDF
 `,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runView,
@@ -63,6 +97,75 @@ func init() {
 	rootCmd.Flags().StringVar(&mermaidMode, "mermaid-mode", "terminal", "Mermaid rendering mode: terminal (default), svg, png, url")
 	rootCmd.Flags().StringVar(&mermaidOutDir, "mermaid-output-dir", os.TempDir(), "Directory for exported diagram files (default: system temp directory)")
 	rootCmd.Flags().BoolVarP(&keepMermaidFiles, "keep-mermaid-files", "k", false, "Save Mermaid diagram files (SVG/PNG) to disk")
+	rootCmd.Flags().BoolVar(&fetchRemoteImages, "fetch-remote-images", false, "Download http(s) images for inline display (cached on disk)")
+	rootCmd.Flags().BoolVar(&offline, "offline", false, "Never access the network (remote images are served from cache only)")
+	rootCmd.Flags().DurationVar(&remoteTimeout, "remote-timeout", utils.DefaultRemoteImageTimeout, "Timeout for downloading a remote image")
+	rootCmd.Flags().StringVar(&remoteMaxSize, "remote-max-size", "5MB", "Size limit for remote images (e.g. 512KB, 10MB)")
+	rootCmd.Flags().StringVar(&imageResampling, "image-resampling", "bilinear", "Image resize filter: bilinear (default), catmull-rom, nearest (pixel art, screenshots of text)")
+	rootCmd.Flags().BoolVar(&noAnimate, "no-animate", false, "Show only the first frame of animated GIFs")
+	rootCmd.Flags().BoolVar(&showComments, "show-comments", false, "Show Obsidian %%comments%% instead of hiding them")
+	rootCmd.Flags().BoolVar(&noFrontmatter, "no-frontmatter", false, "Hide the YAML frontmatter metadata table")
+	rootCmd.Flags().BoolVar(&toc, "toc", false, "Insert a table of contents after the first H1 (or at a [[TOC]] / <!-- toc --> marker)")
+	rootCmd.Flags().BoolVar(&outline, "outline", false, "Print only the heading tree with line numbers")
+	rootCmd.Flags().StringVar(&section, "section", "", "Render only the section under this heading (anchor or fuzzy heading text)")
+	rootCmd.Flags().StringVar(&hyperlinks, "hyperlinks", "auto", "Clickable OSC 8 links: auto (if the terminal supports them), always, never")
+	rootCmd.Flags().StringVar(&pdfPaper, "pdf-paper", "letter", "PDF paper size: letter, legal, tabloid, a3, a4, a5 or WxH (e.g. 6inx9in, 210mmx297mm)")
+	rootCmd.Flags().BoolVar(&pdfLandscape, "pdf-landscape", false, "Landscape PDF pages")
+	rootCmd.Flags().StringVar(&pdfMargins, "pdf-margins", "0.4in", "PDF page margins: one to four lengths in CSS order (e.g. 15mm, \"1in 0.5in\")")
+	rootCmd.Flags().Float64Var(&pdfScale, "pdf-scale", 1, "PDF content scale, 0.1 to 2")
+	rootCmd.Flags().StringVar(&pdfHeader, "pdf-header", "", "PDF page header: html/template text or file ({{.Title}}, {{.Date}}, {{.Label}}, {{.Logo}}, {{.PageNumber}}, {{.TotalPages}})")
+	rootCmd.Flags().StringVar(&pdfFooter, "pdf-footer", "", "PDF page footer: html/template text or file (same fields as --pdf-header)")
+	rootCmd.Flags().StringVar(&pdfLabel, "pdf-label", "", "Classification label printed in the PDF header and footer (e.g. CONFIDENTIAL)")
+	rootCmd.Flags().StringVar(&pdfLogo, "pdf-logo", "", "Logo image shown in the PDF header")
+	rootCmd.Flags().BoolVar(&pdfPageNumbers, "pdf-page-numbers", false, "Print \"Page N of M\" in the PDF footer")
+	rootCmd.Flags().BoolVar(&pdfTOC, "pdf-toc", false, "Start the PDF with a contents page listing the page number of each heading")
+	rootCmd.Flags().StringVar(&pdfTheme, "pdf-theme", "github", "PDF theme: "+strings.Join(renderer.PrintThemes(), ", "))
+	rootCmd.Flags().StringVar(&cssFile, "css", "", "Stylesheet added to the PDF theme (brand fonts and colors)")
+	rootCmd.Flags().BoolVar(&cssReplace, "css-replace", false, "Use the --css stylesheet instead of the built-in PDF styles")
+	rootCmd.Flags().StringVar(&htmlTemplate, "html-template", "", "html/template file for the PDF's HTML document ({{.Title}}, {{.Content}}, {{.TOC}}, {{.Meta}}, {{.Style}}, {{.Scripts}})")
+	rootCmd.Flags().StringVar(&pdfCodeStyle, "pdf-code-style", "", "Chroma style of PDF code blocks, e.g. github, monokai, dracula (default: github, github-dark with the dark theme)")
+	rootCmd.Flags().BoolVar(&pdfLineNumbers, "pdf-line-numbers", false, "Number the lines of PDF code blocks")
+	rootCmd.Flags().StringVar(&mermaidTheme, "mermaid-theme", "default", "Mermaid theme for SVG/PNG/PDF diagrams: default, dark, forest, neutral")
+
+	// The export command shares the rendering and PDF flags
+	for _, name := range exportFlags {
+		exportCmd.Flags().AddFlag(rootCmd.Flags().Lookup(name))
+	}
+	rootCmd.AddCommand(exportCmd)
+	rootCmd.CompletionOptions.DisableDefaultCmd = true
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export output.pdf file.md... | SUMMARY.md | book.yaml",
+	Short: "Export several markdown files as one PDF book",
+	Long: `Export renders markdown files into a single PDF. Every chapter starts on a
+new page, links between the files become links within the PDF, and the
+bookmark outline covers all chapters.
+
+The chapters are the files in the order given, the files linked from a
+SUMMARY.md, or the chapters of a YAML manifest:
+
+  title: Onboarding handbook
+  author: Platform team
+  chapters:
+    - welcome.md
+    - setup/laptop.md
+
+Examples:
+  mdviewer export handbook.pdf intro.md setup.md faq.md
+  mdviewer export handbook.pdf docs/SUMMARY.md --pdf-toc
+  mdviewer export handbook.pdf book.yaml --pdf-paper a4 --pdf-page-numbers
+`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: runExport,
+}
+
+// exportFlags are the flags of the root command that also apply to export
+var exportFlags = []string{
+	"show-comments", "no-frontmatter", "toc", "mermaid-theme",
+	"pdf-paper", "pdf-landscape", "pdf-margins", "pdf-scale",
+	"pdf-header", "pdf-footer", "pdf-label", "pdf-logo", "pdf-page-numbers", "pdf-toc",
+	"pdf-theme", "css", "css-replace", "html-template", "pdf-code-style", "pdf-line-numbers",
 }
 
 func runView(cmd *cobra.Command, args []string) error {
@@ -80,19 +183,70 @@ func runView(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// "file.md#anchor" selects a section, unless the # is part of the name
+	if path, anchor, ok := splitAnchor(inputPath); ok {
+		inputPath = path
+		if section == "" {
+			section = anchor
+		}
+	}
+
 	// Auto-detect terminal width if not specified
 	if width == 0 {
 		width = utils.GetTerminalWidth()
 	}
 
+	if _, err := utils.ParseResampleFilter(imageResampling); err != nil {
+		return err
+	}
+
+	useHyperlinks, err := hyperlinksEnabled(hyperlinks)
+	if err != nil {
+		return err
+	}
+
+	if remoteTimeout <= 0 {
+		return fmt.Errorf("invalid --remote-timeout %s (use a positive duration such as 30s)", remoteTimeout)
+	}
+	remoteMaxBytes, err := utils.ParseByteSize(remoteMaxSize)
+	if err != nil {
+		return fmt.Errorf("invalid --remote-max-size: %w", err)
+	}
+
 	// Create renderer
 	rendererOpts := renderer.RenderOptions{
-		Style:            style,
-		Width:            width,
-		NoMermaid:        noMermaid,
-		MermaidMode:      mermaidMode,
-		MermaidOutDir:    mermaidOutDir,
-		KeepMermaidFiles: keepMermaidFiles,
+		Style:             style,
+		Width:             width,
+		NoMermaid:         noMermaid,
+		MermaidMode:       mermaidMode,
+		MermaidOutDir:     mermaidOutDir,
+		KeepMermaidFiles:  keepMermaidFiles,
+		FetchRemoteImages: fetchRemoteImages,
+		Offline:           offline,
+		RemoteTimeout:     remoteTimeout,
+		RemoteMaxBytes:    remoteMaxBytes,
+		ImageResampling:   imageResampling,
+		NoAnimate:         noAnimate,
+		ShowComments:      showComments,
+		NoFrontmatter:     noFrontmatter,
+		MermaidTheme:      mermaidTheme,
+		TOC:               toc,
+		Section:           section,
+		Hyperlinks:        useHyperlinks,
+		PDFTOC:            pdfTOC,
+	}
+
+	// Per-document settings from the frontmatter; explicit flags win
+	page := pdf.DefaultPageOptions()
+	var headerFooter pdf.HeaderFooterOptions
+	var pdfStyle pdf.StyleOptions
+	if inputPath != "-" {
+		if docOpts := renderer.ReadDocumentOptions(inputPath); docOpts != nil {
+			docOpts.Apply(&rendererOpts, cmd.Flags().Changed)
+			page = pdf.DocumentPageOptions(docOpts.PDF)
+			headerFooter = pdf.DocumentHeaderFooterOptions(docOpts.PDF, filepath.Dir(inputPath))
+			pdfStyle = pdf.DocumentStyleOptions(docOpts.PDF, filepath.Dir(inputPath))
+		}
 	}
 
 	mdRenderer, err := renderer.NewRenderer(rendererOpts)
@@ -100,13 +254,36 @@ func runView(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to create renderer: %w", err)
 	}
 
+	// Print only the heading tree
+	if outline {
+		content, err := utils.ReadFile(inputPath)
+		if err != nil {
+			return err
+		}
+		fmt.Print(mdRenderer.RenderOutline(string(content)))
+		return nil
+	}
+
 	// Handle PDF export
 	if exportPDF != "" {
-		return exportToPDF(inputPath, exportPDF)
+		page, err := applyPageFlags(cmd, page)
+		if err != nil {
+			return err
+		}
+		headerFooter, err := applyHeaderFooterFlags(cmd, headerFooter)
+		if err != nil {
+			return err
+		}
+		pdfStyle, err := applyStyleFlags(cmd, pdfStyle)
+		if err != nil {
+			return err
+		}
+		return exportToPDF(inputPath, exportPDF, rendererOpts, page, headerFooter, pdfStyle)
 	}
 
-	// Handle mermaid diagram opening if requested
-	if openMermaid && !noMermaid {
+	// Handle mermaid diagram opening if requested (needs mermaid.live, so
+	// it is skipped in offline mode)
+	if openMermaid && !noMermaid && !offline {
 		// Read content to detect mermaid diagrams
 		content, err := utils.ReadFile(inputPath)
 		if err != nil {
@@ -141,13 +318,186 @@ func runView(cmd *cobra.Command, args []string) error {
 	return simpleViewer.ViewFile(inputPath)
 }
 
-func exportToPDF(inputPath, outputPath string) error {
-	// Create PDF exporter
+// splitAnchor splits "file.md#anchor" into the file and the anchor. It
+// reports false for stdin, paths without a #, and files whose name contains #.
+func splitAnchor(path string) (string, string, bool) {
+	idx := strings.LastIndex(path, "#")
+	if path == "-" || idx == -1 {
+		return "", "", false
+	}
+	if _, err := os.Stat(path); err == nil {
+		return "", "", false
+	}
+	return path[:idx], path[idx+1:], true
+}
+
+// hyperlinksEnabled resolves the --hyperlinks mode
+func hyperlinksEnabled(mode string) (bool, error) {
+	switch mode {
+	case "auto":
+		return utils.SupportsHyperlinks(), nil
+	case "always":
+		return true, nil
+	case "never":
+		return false, nil
+	default:
+		return false, fmt.Errorf("invalid --hyperlinks mode %q (use auto, always or never)", mode)
+	}
+}
+
+// applyPageFlags overrides the PDF page settings with the --pdf-* flags given
+// on the command line. An explicit --pdf-paper also wins over CSS @page sizes.
+func applyPageFlags(cmd *cobra.Command, page pdf.PageOptions) (pdf.PageOptions, error) {
+	flags := cmd.Flags()
+	if flags.Changed("pdf-paper") {
+		page.Paper = pdfPaper
+		page.PreferCSSPageSize = false
+	}
+	if flags.Changed("pdf-landscape") {
+		page.Landscape = pdfLandscape
+	}
+	if flags.Changed("pdf-margins") {
+		margins, err := pdf.ParseMargins(pdfMargins)
+		if err != nil {
+			return page, err
+		}
+		page.Margins = margins
+	}
+	if flags.Changed("pdf-scale") {
+		page.Scale = pdfScale
+	}
+	return page, page.Validate()
+}
+
+// applyHeaderFooterFlags overrides the PDF header and footer settings with
+// the flags given on the command line
+func applyHeaderFooterFlags(cmd *cobra.Command, headerFooter pdf.HeaderFooterOptions) (pdf.HeaderFooterOptions, error) {
+	flags := cmd.Flags()
+	if flags.Changed("pdf-header") {
+		headerFooter.Header = pdf.TemplateArg(pdfHeader, ".")
+	}
+	if flags.Changed("pdf-footer") {
+		headerFooter.Footer = pdf.TemplateArg(pdfFooter, ".")
+	}
+	if flags.Changed("pdf-label") {
+		headerFooter.Label = pdfLabel
+	}
+	if flags.Changed("pdf-logo") {
+		headerFooter.Logo = pdfLogo
+	}
+	if flags.Changed("pdf-page-numbers") {
+		headerFooter.PageNumbers = pdfPageNumbers
+	}
+	return headerFooter, headerFooter.Validate()
+}
+
+// applyStyleFlags overrides the PDF theme, stylesheet and code style with
+// the flags given on the command line, and reads the document template
+func applyStyleFlags(cmd *cobra.Command, style pdf.StyleOptions) (pdf.StyleOptions, error) {
+	flags := cmd.Flags()
+	if flags.Changed("pdf-theme") {
+		style.Theme = pdfTheme
+	}
+	if flags.Changed("pdf-code-style") {
+		style.CodeStyle = pdfCodeStyle
+	}
+	if flags.Changed("pdf-line-numbers") {
+		style.LineNumbers = pdfLineNumbers
+	}
+	if flags.Changed("css") {
+		css, err := os.ReadFile(cssFile)
+		if err != nil {
+			return style, fmt.Errorf("failed to read stylesheet: %w", err)
+		}
+		style.CSS = string(css)
+	}
+	if flags.Changed("css-replace") {
+		if cssReplace && style.CSS == "" {
+			return style, fmt.Errorf("--css-replace needs a stylesheet (--css)")
+		}
+		style.ReplaceCSS = cssReplace
+	}
+	if flags.Changed("html-template") {
+		tmpl, err := os.ReadFile(htmlTemplate)
+		if err != nil {
+			return style, fmt.Errorf("failed to read HTML template: %w", err)
+		}
+		style.Template = string(tmpl)
+	}
+	return style, nil
+}
+
+// runExport exports the chapters of a book to one PDF
+func runExport(cmd *cobra.Command, args []string) error {
+	outputPath := args[0]
+	if !strings.EqualFold(filepath.Ext(outputPath), ".pdf") {
+		return fmt.Errorf("the first argument must be the output PDF file, got %s", outputPath)
+	}
+	book, err := renderer.ReadBook(args[1:])
+	if err != nil {
+		return err
+	}
+
+	page, err := applyPageFlags(cmd, pdf.DefaultPageOptions())
+	if err != nil {
+		return err
+	}
+	headerFooter, err := applyHeaderFooterFlags(cmd, pdf.HeaderFooterOptions{})
+	if err != nil {
+		return err
+	}
+	style, err := applyStyleFlags(cmd, pdf.StyleOptions{})
+	if err != nil {
+		return err
+	}
+	opts := renderer.RenderOptions{
+		ShowComments:  showComments,
+		NoFrontmatter: noFrontmatter,
+		MermaidTheme:  mermaidTheme,
+		TOC:           toc,
+		PDFTOC:        pdfTOC,
+	}
+	exporter, err := newExporter(opts, page, headerFooter, style)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Generating PDF from %d chapter(s)...\n", len(book.Chapters))
+	if err := exporter.ExportBookToPDF(book, outputPath); err != nil {
+		return fmt.Errorf("PDF export failed: %w", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "PDF successfully exported to %s\n", outputPath)
+	return nil
+}
+
+// newExporter creates a PDF exporter with the rendering and page settings
+func newExporter(opts renderer.RenderOptions, page pdf.PageOptions, headerFooter pdf.HeaderFooterOptions, style pdf.StyleOptions) (*pdf.Exporter, error) {
 	exporter := pdf.NewExporter()
+	exporter.SetShowComments(opts.ShowComments)
+	exporter.SetHideFrontmatter(opts.NoFrontmatter)
+	exporter.SetMermaidTheme(opts.MermaidTheme)
+	exporter.SetTOC(opts.TOC)
+	exporter.SetSection(opts.Section)
+	exporter.SetPrintTOC(opts.PDFTOC)
+	exporter.SetPageOptions(page)
+	exporter.SetHeaderFooter(headerFooter)
+	if err := exporter.SetStyle(style); err != nil {
+		return nil, err
+	}
+	return exporter, nil
+}
+
+func exportToPDF(inputPath, outputPath string, opts renderer.RenderOptions, page pdf.PageOptions, headerFooter pdf.HeaderFooterOptions, style pdf.StyleOptions) error {
+	// Create PDF exporter
+	exporter, err := newExporter(opts, page, headerFooter, style)
+	if err != nil {
+		return err
+	}
 
 	// Export to PDF
 	fmt.Fprintf(os.Stderr, "Generating PDF from %s...\n", inputPath)
-	err := exporter.ExportFileToPDF(inputPath, outputPath)
+	err = exporter.ExportFileToPDF(inputPath, outputPath)
 	if err != nil {
 		return fmt.Errorf("PDF export failed: %w", err)
 	}
@@ -0,0 +1,65 @@
+package katex
+
+import (
+	"embed"
+	"encoding/base64"
+	"path"
+	"regexp"
+)
+
+// assets/ holds the KaTeX distribution: katex.min.js, katex.min.css and the
+// fonts/ directory (see assets/README.md)
+//
+//go:embed assets
+var assets embed.FS
+
+// fontURLRegexp matches the font references in katex.min.css
+var fontURLRegexp = regexp.MustCompile(`url\((fonts/[^)]+)\)`)
+
+// Available reports whether the KaTeX bundle is embedded
+func Available() bool {
+	_, err := assets.ReadFile("assets/katex.min.js")
+	return err == nil
+}
+
+// Script returns katex.min.js, or "" if it is not embedded
+func Script() string {
+	data, err := assets.ReadFile("assets/katex.min.js")
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// Stylesheet returns katex.min.css with its fonts inlined as data URIs, so
+// it works in a document without a base URL. Fonts that are not embedded
+// are left as they are.
+func Stylesheet() string {
+	data, err := assets.ReadFile("assets/katex.min.css")
+	if err != nil {
+		return ""
+	}
+	return fontURLRegexp.ReplaceAllStringFunc(string(data), func(match string) string {
+		name := fontURLRegexp.FindStringSubmatch(match)[1]
+		font, err := assets.ReadFile(path.Join("assets", name))
+		if err != nil {
+			return match
+		}
+		mime := "font/" + path.Ext(name)[1:]
+		if path.Ext(name) == ".ttf" {
+			mime = "font/ttf"
+		}
+		return "url(data:" + mime + ";base64," + base64.StdEncoding.EncodeToString(font) + ")"
+	})
+}
+
+// RenderScript returns the script that typesets the math elements produced
+// by the renderer (class "math", TeX source in data-tex) with KaTeX
+func RenderScript() string {
+	return `document.querySelectorAll('.math[data-tex]').forEach(function (el) {
+	katex.render(el.dataset.tex, el, {
+		displayMode: el.classList.contains('math-display'),
+		throwOnError: false
+	});
+});`
+}
@@ -6,17 +6,40 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/aquele_dinho/mdviewer/internal/utils"
 	"github.com/chromedp/chromedp"
 )
 
 // Compiler renders Mermaid diagrams to SVG using chromedp
 type Compiler struct {
-	// Empty struct - we'll create fresh contexts per render
+	// Fresh contexts are created per render
+	theme string // Mermaid theme name
 }
 
 // NewCompiler creates a new Mermaid compiler with chromedp
 func NewCompiler() (*Compiler, error) {
-	return &Compiler{}, nil
+	return &Compiler{theme: "default"}, nil
+}
+
+// SetTheme sets the Mermaid theme ("default", "dark", "forest", "neutral",
+// "base"); an empty name keeps the default theme
+func (c *Compiler) SetTheme(theme string) {
+	if theme != "" {
+		c.theme = theme
+	}
+}
+
+// initScript returns the JavaScript that initializes mermaid
+func (c *Compiler) initScript() string {
+	themeJSON, _ := json.Marshal(c.theme)
+	return fmt.Sprintf(`
+			mermaid.initialize({
+				startOnLoad: false,
+				theme: %s,
+				securityLevel: 'loose'
+			});
+			window.mermaidResult = null;
+		`, themeJSON)
 }
 
 
@@ -43,14 +66,7 @@ func (c *Compiler) Render(diagramCode string) (*SVGResult, error) {
 		// Load mermaid.js
 		chromedp.Evaluate(MermaidJS, nil),
 		// Initialize mermaid
-		chromedp.Evaluate(`
-			mermaid.initialize({
-				startOnLoad: false,
-				theme: 'default',
-				securityLevel: 'loose'
-			});
-			window.mermaidResult = null;
-		`, nil),
+		chromedp.Evaluate(c.initScript(), nil),
 		// Start rendering the diagram
 		chromedp.Evaluate(fmt.Sprintf(`
 			(async function() {
@@ -91,7 +107,7 @@ func (c *Compiler) Render(diagramCode string) (*SVGResult, error) {
 
 	// Clean and extract dimensions
 	svg = CleanSVG(svg)
-	width, height := ExtractSVGDimensions(svg)
+	width, height := utils.SVGDimensions(svg)
 
 	return &SVGResult{
 		SVG:    svg,
@@ -126,14 +142,7 @@ func (c *Compiler) RenderToPNG(diagramCode string, width, height int) ([]byte, e
 		// Load mermaid.js
 		chromedp.Evaluate(MermaidJS, nil),
 		// Initialize mermaid
-		chromedp.Evaluate(`
-			mermaid.initialize({
-				startOnLoad: false,
-				theme: 'default',
-				securityLevel: 'loose'
-			});
-			window.mermaidResult = null;
-		`, nil),
+		chromedp.Evaluate(c.initScript(), nil),
 		// Render and inject into DOM
 		chromedp.Evaluate(fmt.Sprintf(`
 			(async function() {
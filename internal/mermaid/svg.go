@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strings"
 )
 
@@ -16,33 +15,6 @@ type SVGResult struct {
 	Error  error
 }
 
-// ExtractSVGDimensions extracts width and height from SVG content
-func ExtractSVGDimensions(svg string) (width, height int) {
-	// Default dimensions
-	width, height = 800, 600
-
-	// Try to extract from viewBox
-	viewBoxRegex := regexp.MustCompile(`viewBox="[^"]*\s+([0-9.]+)\s+([0-9.]+)"`)
-	if matches := viewBoxRegex.FindStringSubmatch(svg); len(matches) == 3 {
-		fmt.Sscanf(matches[1], "%d", &width)
-		fmt.Sscanf(matches[2], "%d", &height)
-		return
-	}
-
-	// Try to extract from width/height attributes
-	widthRegex := regexp.MustCompile(`width="([0-9.]+)"`)
-	heightRegex := regexp.MustCompile(`height="([0-9.]+)"`)
-
-	if matches := widthRegex.FindStringSubmatch(svg); len(matches) == 2 {
-		fmt.Sscanf(matches[1], "%d", &width)
-	}
-	if matches := heightRegex.FindStringSubmatch(svg); len(matches) == 2 {
-		fmt.Sscanf(matches[1], "%d", &height)
-	}
-
-	return
-}
-
 // SaveSVGToFile saves SVG content to a file
 func SaveSVGToFile(svg, outputPath string) error {
 	// Ensure directory exists
@@ -3,24 +3,174 @@ package pdf
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/runtime"
 	"github.com/chromedp/chromedp"
 )
 
 // ChromeDPExporter uses headless Chrome to generate PDFs
 type ChromeDPExporter struct {
-	timeout time.Duration
+	timeout      time.Duration
+	page         PageOptions
+	headerFooter HeaderFooterOptions
+}
+
+// PageOptions controls the paper size, orientation, margins and scale of the
+// PDF
+type PageOptions struct {
+	Paper             string  // Paper size: "letter", "legal", "tabloid", "a3", "a4", "a5" or "WxH"
+	Landscape         bool    // Rotate the paper to landscape
+	Margins           Margins // Page margins
+	Scale             float64 // Scale of the page content, 0.1 to 2
+	PreferCSSPageSize bool    // Let CSS @page size rules override the paper size
+}
+
+// Margins are the page margins, in inches
+type Margins struct {
+	Top, Right, Bottom, Left float64
+}
+
+// DefaultPageOptions returns US Letter, portrait, with 0.4" margins, at
+// 100% scale. CSS @page size rules take precedence.
+func DefaultPageOptions() PageOptions {
+	return PageOptions{
+		Paper:             "letter",
+		Margins:           Margins{Top: 0.4, Right: 0.4, Bottom: 0.4, Left: 0.4},
+		Scale:             1,
+		PreferCSSPageSize: true,
+	}
+}
+
+// Scale limits accepted by Chrome
+const (
+	MinScale = 0.1
+	MaxScale = 2
+)
+
+// Validate checks the paper size and scale
+func (o PageOptions) Validate() error {
+	if _, _, err := PaperSize(o.Paper); err != nil {
+		return err
+	}
+	if o.Scale < MinScale || o.Scale > MaxScale {
+		return fmt.Errorf("invalid scale %g (use a value from %g to %g)", o.Scale, float64(MinScale), float64(MaxScale))
+	}
+	return nil
+}
+
+// paperSizes maps paper size names to width and height in inches
+var paperSizes = map[string][2]float64{
+	"letter":  {8.5, 11},
+	"legal":   {8.5, 14},
+	"tabloid": {11, 17},
+	"a3":      {11.69, 16.54},
+	"a4":      {8.27, 11.69},
+	"a5":      {5.83, 8.27},
+}
+
+// PaperSize returns the portrait width and height of a paper size, in inches.
+// The size is a name such as "a4", or a custom "WxH" size such as "6x9" or
+// "210mmx297mm".
+func PaperSize(name string) (float64, float64, error) {
+	if size, ok := paperSizes[strings.ToLower(name)]; ok {
+		return size[0], size[1], nil
+	}
+
+	if w, h, ok := strings.Cut(strings.ToLower(name), "x"); ok {
+		width, wErr := ParseLength(w)
+		height, hErr := ParseLength(h)
+		if wErr == nil && hErr == nil && width > 0 && height > 0 {
+			return width, height, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("unknown paper size %q (use letter, legal, tabloid, a3, a4, a5 or WxH such as 210mmx297mm)", name)
+}
+
+// ParseLength parses a length such as "0.5in", "15mm", "1.5cm" or "36pt"
+// into inches. A bare number is taken as inches.
+func ParseLength(length string) (float64, error) {
+	s := strings.TrimSpace(strings.ToLower(length))
+	units := []struct {
+		suffix string
+		inches float64
+	}{
+		{"in", 1},
+		{"mm", 1 / 25.4},
+		{"cm", 1 / 2.54},
+		{"pt", 1.0 / 72},
+		{"px", 1.0 / 96},
+	}
+	factor := 1.0
+	for _, unit := range units {
+		if strings.HasSuffix(s, unit.suffix) {
+			s = strings.TrimSpace(strings.TrimSuffix(s, unit.suffix))
+			factor = unit.inches
+			break
+		}
+	}
+
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid length %q (use a value such as 0.5in, 15mm or 1cm)", length)
+	}
+	return value * factor, nil
+}
+
+// ParseMargins parses one to four lengths in CSS order: "1cm" for all sides,
+// "1cm 2cm" for top/bottom and left/right, "1cm 2cm 3cm" for top,
+// left/right and bottom, or "1cm 2cm 3cm 4cm" for top, right, bottom, left.
+// Values may also be separated by commas.
+func ParseMargins(margins string) (Margins, error) {
+	fields := strings.FieldsFunc(margins, func(r rune) bool {
+		return r == ' ' || r == '\t' || r == ','
+	})
+	if len(fields) == 0 || len(fields) > 4 {
+		return Margins{}, fmt.Errorf("invalid margins %q (use one to four lengths such as \"15mm\" or \"0.5in 1in\")", margins)
+	}
+
+	values := make([]float64, len(fields))
+	for i, field := range fields {
+		value, err := ParseLength(field)
+		if err != nil {
+			return Margins{}, err
+		}
+		values[i] = value
+	}
+
+	switch len(values) {
+	case 1:
+		return Margins{values[0], values[0], values[0], values[0]}, nil
+	case 2:
+		return Margins{values[0], values[1], values[0], values[1]}, nil
+	case 3:
+		return Margins{values[0], values[1], values[2], values[1]}, nil
+	default:
+		return Margins{values[0], values[1], values[2], values[3]}, nil
+	}
 }
 
 // NewChromeDPExporter creates a new ChromeDP-based PDF exporter
 func NewChromeDPExporter() *ChromeDPExporter {
 	return &ChromeDPExporter{
 		timeout: 30 * time.Second,
+		page:    DefaultPageOptions(),
 	}
 }
 
+// SetPageOptions sets the paper size, orientation, margins and scale
+func (e *ChromeDPExporter) SetPageOptions(page PageOptions) {
+	e.page = page
+}
+
+// SetHeaderFooter sets the running header and footer of the pages
+func (e *ChromeDPExporter) SetHeaderFooter(headerFooter HeaderFooterOptions) {
+	e.headerFooter = headerFooter
+}
+
 // GeneratePDF generates a PDF from HTML content using headless Chrome
 func (e *ChromeDPExporter) GeneratePDF(htmlContent string) ([]byte, error) {
 	// Create context with timeout
@@ -31,10 +181,40 @@ func (e *ChromeDPExporter) GeneratePDF(htmlContent string) ([]byte, error) {
 	allocCtx, allocCancel := chromedp.NewContext(ctx)
 	defer allocCancel()
 
+	paperWidth, paperHeight, err := PaperSize(e.page.Paper)
+	if err != nil {
+		return nil, err
+	}
+	if e.page.Landscape {
+		paperWidth, paperHeight = paperHeight, paperWidth
+	}
+	scale := e.page.Scale
+	if scale == 0 {
+		scale = 1
+	}
+
+	margins := e.page.Margins
+	var headerTemplate, footerTemplate string
+	if e.headerFooter.Enabled() {
+		headerTemplate, footerTemplate, err = e.headerFooter.templates(margins)
+		if err != nil {
+			return nil, err
+		}
+		// Headers and footers are drawn inside the top and bottom margins
+		margins.Top = max(margins.Top, minHeaderFooterMargin)
+		margins.Bottom = max(margins.Bottom, minHeaderFooterMargin)
+	}
+
 	var pdfBuffer []byte
 
+	// Fill in the page numbers of a printed table of contents before printing
+	var tocPageNumbers chromedp.Action = chromedp.ActionFunc(func(context.Context) error { return nil })
+	if hasPrintTOC(htmlContent) {
+		tocPageNumbers = fillTOCPageNumbers(paperWidth, paperHeight, margins, scale, e.page.PreferCSSPageSize)
+	}
+
 	// Generate PDF
-	err := chromedp.Run(allocCtx,
+	err = chromedp.Run(allocCtx,
 		chromedp.Navigate("about:blank"),
 		chromedp.ActionFunc(func(ctx context.Context) error {
 			// Get the frame tree to set document content
@@ -46,17 +226,29 @@ func (e *ChromeDPExporter) GeneratePDF(htmlContent string) ([]byte, error) {
 			// Set the HTML content
 			return page.SetDocumentContent(frameTree.Frame.ID, htmlContent).Do(ctx)
 		}),
+		// Wait for web fonts (such as KaTeX's) before printing
+		chromedp.Evaluate(`document.fonts.ready.then(() => true)`, nil, func(p *runtime.EvaluateParams) *runtime.EvaluateParams {
+			return p.WithAwaitPromise(true)
+		}),
+		tocPageNumbers,
 		chromedp.ActionFunc(func(ctx context.Context) error {
 			// Generate PDF with print options
 			buf, _, err := page.PrintToPDF().
 				WithPrintBackground(true).
-				WithPreferCSSPageSize(false).
-				WithPaperWidth(8.5).   // US Letter width in inches
-				WithPaperHeight(11.0). // US Letter height in inches
-				WithMarginTop(0.4).
-				WithMarginBottom(0.4).
-				WithMarginLeft(0.4).
-				WithMarginRight(0.4).
+				WithPreferCSSPageSize(e.page.PreferCSSPageSize).
+				WithPaperWidth(paperWidth). // Inches
+				WithPaperHeight(paperHeight).
+				WithMarginTop(margins.Top).
+				WithMarginBottom(margins.Bottom).
+				WithMarginLeft(margins.Left).
+				WithMarginRight(margins.Right).
+				WithScale(scale).
+				WithDisplayHeaderFooter(e.headerFooter.Enabled()).
+				WithHeaderTemplate(headerTemplate).
+				WithFooterTemplate(footerTemplate).
+				// Bookmarks from the headings; Chrome needs a tagged PDF for them
+				WithGenerateTaggedPDF(true).
+				WithGenerateDocumentOutline(true).
 				Do(ctx)
 
 			if err != nil {
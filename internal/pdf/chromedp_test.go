@@ -0,0 +1,113 @@
+package pdf
+
+import (
+	"math"
+	"testing"
+)
+
+// approx reports whether a and b are equal to within rounding
+func approx(a, b float64) bool {
+	return math.Abs(a-b) < 1e-3
+}
+
+func TestPaperSize(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height float64
+		ok            bool
+	}{
+		{"letter", 8.5, 11, true},
+		{"A4", 8.27, 11.69, true},
+		{"6x9", 6, 9, true},
+		{"6inx9in", 6, 9, true},
+		{"210mmx297mm", 8.268, 11.693, true},
+		{"14.8cm x 21cm", 5.827, 8.268, true},
+		{"b7", 0, 0, false},
+		{"0x9", 0, 0, false},
+		{"6x", 0, 0, false},
+		{"", 0, 0, false},
+	}
+	for _, tt := range tests {
+		width, height, err := PaperSize(tt.name)
+		if (err == nil) != tt.ok {
+			t.Errorf("PaperSize(%q) error = %v, want ok = %v", tt.name, err, tt.ok)
+			continue
+		}
+		if !approx(width, tt.width) || !approx(height, tt.height) {
+			t.Errorf("PaperSize(%q) = %gx%g, want %gx%g", tt.name, width, height, tt.width, tt.height)
+		}
+	}
+}
+
+func TestParseLength(t *testing.T) {
+	tests := []struct {
+		length string
+		want   float64
+		ok     bool
+	}{
+		{"1", 1, true},
+		{"0.5in", 0.5, true},
+		{"25.4mm", 1, true},
+		{"2.54cm", 1, true},
+		{"72pt", 1, true},
+		{"96px", 1, true},
+		{" 1 IN ", 1, true},
+		{"-1in", 0, false},
+		{"1ft", 0, false},
+		{"mm", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseLength(tt.length)
+		if (err == nil) != tt.ok || !approx(got, tt.want) {
+			t.Errorf("ParseLength(%q) = %g, %v, want %g, ok = %v", tt.length, got, err, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseMargins(t *testing.T) {
+	tests := []struct {
+		margins string
+		want    Margins
+		ok      bool
+	}{
+		{"1in", Margins{1, 1, 1, 1}, true},
+		{"1in 0.5in", Margins{1, 0.5, 1, 0.5}, true},
+		{"1in 0.5in 2in", Margins{1, 0.5, 2, 0.5}, true},
+		{"1in 0.5in 2in 0.25in", Margins{1, 0.5, 2, 0.25}, true},
+		{"1in,0.5in", Margins{1, 0.5, 1, 0.5}, true},
+		{"25.4mm 0", Margins{1, 0, 1, 0}, true},
+		{"", Margins{}, false},
+		{"1 2 3 4 5", Margins{}, false},
+		{"1in wide", Margins{}, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseMargins(tt.margins)
+		if (err == nil) != tt.ok {
+			t.Errorf("ParseMargins(%q) error = %v, want ok = %v", tt.margins, err, tt.ok)
+			continue
+		}
+		if !approx(got.Top, tt.want.Top) || !approx(got.Right, tt.want.Right) ||
+			!approx(got.Bottom, tt.want.Bottom) || !approx(got.Left, tt.want.Left) {
+			t.Errorf("ParseMargins(%q) = %+v, want %+v", tt.margins, got, tt.want)
+		}
+	}
+}
+
+func TestPageOptionsValidate(t *testing.T) {
+	tests := []struct {
+		page PageOptions
+		ok   bool
+	}{
+		{DefaultPageOptions(), true},
+		{PageOptions{Paper: "a5", Scale: MinScale}, true},
+		{PageOptions{Paper: "a5", Scale: MaxScale}, true},
+		{PageOptions{Paper: "a5", Scale: 0}, false},
+		{PageOptions{Paper: "a5", Scale: 2.5}, false},
+		{PageOptions{Paper: "b7", Scale: 1}, false},
+	}
+	for _, tt := range tests {
+		if err := tt.page.Validate(); (err == nil) != tt.ok {
+			t.Errorf("Validate(%+v) = %v, want ok = %v", tt.page, err, tt.ok)
+		}
+	}
+}
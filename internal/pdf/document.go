@@ -0,0 +1,122 @@
+package pdf
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/aquele_dinho/mdviewer/internal/renderer"
+)
+
+// DocumentPageOptions builds the page settings from a document's
+// frontmatter, keeping the defaults for invalid values
+func DocumentPageOptions(doc renderer.DocumentPDFOptions) PageOptions {
+	page := DefaultPageOptions()
+	if doc.Paper != "" {
+		if _, _, err := PaperSize(doc.Paper); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		} else {
+			page.Paper = doc.Paper
+		}
+	}
+	if doc.Landscape != nil {
+		page.Landscape = *doc.Landscape
+	}
+	if doc.Margin != "" {
+		if margins, err := ParseMargins(doc.Margin); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		} else {
+			page.Margins = margins
+		}
+	}
+	if doc.Scale != 0 {
+		if doc.Scale < MinScale || doc.Scale > MaxScale {
+			fmt.Fprintf(os.Stderr, "Warning: invalid PDF scale %g (use a value from %g to %g)\n", doc.Scale, float64(MinScale), float64(MaxScale))
+		} else {
+			page.Scale = doc.Scale
+		}
+	}
+	return page
+}
+
+// DocumentHeaderFooterOptions builds the header and footer settings from a
+// document's frontmatter. Template and logo files are relative to dir, the
+// document's directory; invalid settings are reported as warnings and left
+// out.
+func DocumentHeaderFooterOptions(doc renderer.DocumentPDFOptions, dir string) HeaderFooterOptions {
+	headerFooter := HeaderFooterOptions{
+		Header: TemplateArg(doc.Header, dir),
+		Footer: TemplateArg(doc.Footer, dir),
+		Label:  doc.Label,
+	}
+	if doc.Logo != "" {
+		headerFooter.Logo = doc.Logo
+		if !filepath.IsAbs(doc.Logo) {
+			headerFooter.Logo = filepath.Join(dir, doc.Logo)
+		}
+	}
+	if doc.PageNumbers != nil {
+		headerFooter.PageNumbers = *doc.PageNumbers
+	}
+	if err := headerFooter.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return HeaderFooterOptions{Label: doc.Label, PageNumbers: headerFooter.PageNumbers}
+	}
+	return headerFooter
+}
+
+// DocumentStyleOptions builds the theme, stylesheet and code style from a
+// document's frontmatter. The stylesheet file is relative to dir, the
+// document's directory; invalid settings are reported as warnings and left
+// out.
+func DocumentStyleOptions(doc renderer.DocumentPDFOptions, dir string) StyleOptions {
+	var style StyleOptions
+	if doc.Theme != "" {
+		if !slices.Contains(renderer.PrintThemes(), strings.ToLower(doc.Theme)) {
+			fmt.Fprintf(os.Stderr, "Warning: unknown PDF theme %q (available: %s)\n", doc.Theme, strings.Join(renderer.PrintThemes(), ", "))
+		} else {
+			style.Theme = doc.Theme
+		}
+	}
+	if doc.CodeStyle != "" {
+		if !slices.Contains(styles.Names(), doc.CodeStyle) {
+			fmt.Fprintf(os.Stderr, "Warning: unknown code style %q\n", doc.CodeStyle)
+		} else {
+			style.CodeStyle = doc.CodeStyle
+		}
+	}
+	if doc.LineNumbers != nil {
+		style.LineNumbers = *doc.LineNumbers
+	}
+	if doc.CSS != "" {
+		path := doc.CSS
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		if css, err := os.ReadFile(path); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to read stylesheet: %v\n", err)
+		} else {
+			style.CSS = string(css)
+		}
+	}
+	return style
+}
+
+// TemplateArg returns the contents of the template file named by value
+// (relative to dir), or value itself if there is no such file
+func TemplateArg(value, dir string) string {
+	if value == "" || strings.ContainsAny(value, "<{") {
+		return value
+	}
+	path := value
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	if content, err := os.ReadFile(path); err == nil {
+		return string(content)
+	}
+	return value
+}
@@ -0,0 +1,85 @@
+package pdf
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aquele_dinho/mdviewer/internal/renderer"
+)
+
+func TestDocumentPageOptions(t *testing.T) {
+	yes := true
+	defaults := DefaultPageOptions()
+	tests := []struct {
+		name string
+		doc  renderer.DocumentPDFOptions
+		want PageOptions
+	}{
+		{"empty", renderer.DocumentPDFOptions{}, defaults},
+		{
+			name: "all settings",
+			doc:  renderer.DocumentPDFOptions{Paper: "a4", Landscape: &yes, Margin: "1in 0.5in", Scale: 0.8},
+			want: PageOptions{Paper: "a4", Landscape: true, Margins: Margins{1, 0.5, 1, 0.5}, Scale: 0.8, PreferCSSPageSize: true},
+		},
+		{"unknown paper", renderer.DocumentPDFOptions{Paper: "b7"}, defaults},
+		{"invalid margin", renderer.DocumentPDFOptions{Margin: "wide"}, defaults},
+		{"scale out of range", renderer.DocumentPDFOptions{Scale: 3}, defaults},
+	}
+	for _, tt := range tests {
+		if got := DocumentPageOptions(tt.doc); got != tt.want {
+			t.Errorf("%s: DocumentPageOptions = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDocumentHeaderFooterOptions(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{"header.html": "<div>{{.Title}}</div>", "logo.png": "\x89PNG\r\n\x1a\n"}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := DocumentHeaderFooterOptions(renderer.DocumentPDFOptions{Header: "header.html", Logo: "logo.png", Label: "Internal"}, dir)
+	if got.Header != "<div>{{.Title}}</div>" {
+		t.Errorf("Header = %q, want the contents of header.html", got.Header)
+	}
+	if want := filepath.Join(dir, "logo.png"); got.Logo != want {
+		t.Errorf("Logo = %q, want %q", got.Logo, want)
+	}
+	if got.Label != "Internal" {
+		t.Errorf("Label = %q, want %q", got.Label, "Internal")
+	}
+
+	// An invalid template keeps only the label and page numbers
+	yes := true
+	got = DocumentHeaderFooterOptions(renderer.DocumentPDFOptions{Footer: "{{.Nope", Label: "Internal", PageNumbers: &yes}, dir)
+	if want := (HeaderFooterOptions{Label: "Internal", PageNumbers: true}); got != want {
+		t.Errorf("invalid footer: options = %+v, want %+v", got, want)
+	}
+}
+
+func TestTemplateArg(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "footer.html"), []byte("from file"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"", ""},
+		{"footer.html", "from file"},
+		{filepath.Join(dir, "footer.html"), "from file"},
+		{"missing.html", "missing.html"},
+		{"Page {{.PageNumber}}", "Page {{.PageNumber}}"},
+		{"<span>footer.html</span>", "<span>footer.html</span>"},
+	}
+	for _, tt := range tests {
+		if got := TemplateArg(tt.value, dir); got != tt.want {
+			t.Errorf("TemplateArg(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
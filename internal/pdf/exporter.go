@@ -11,6 +11,7 @@ import (
 type Exporter struct {
 	htmlRenderer *renderer.HTMLRenderer
 	pdfGenerator *ChromeDPExporter
+	headerFooter HeaderFooterOptions
 }
 
 // NewExporter creates a new PDF exporter
@@ -21,6 +22,73 @@ func NewExporter() *Exporter {
 	}
 }
 
+// SetShowComments controls whether Obsidian %%comments%% are exported
+func (e *Exporter) SetShowComments(show bool) {
+	e.htmlRenderer.SetShowComments(show)
+}
+
+// SetHideFrontmatter controls whether the frontmatter metadata table is exported
+func (e *Exporter) SetHideFrontmatter(hide bool) {
+	e.htmlRenderer.SetHideFrontmatter(hide)
+}
+
+// SetTOC controls whether a table of contents is inserted
+func (e *Exporter) SetTOC(toc bool) {
+	e.htmlRenderer.SetTOC(toc)
+}
+
+// SetPrintTOC controls whether the PDF starts with a contents page listing
+// the page number of each heading
+func (e *Exporter) SetPrintTOC(printTOC bool) {
+	e.htmlRenderer.SetPrintTOC(printTOC)
+}
+
+// SetSection restricts the export to the section under the matching heading
+func (e *Exporter) SetSection(query string) {
+	e.htmlRenderer.SetSection(query)
+}
+
+// SetMermaidTheme sets the theme diagrams are rendered with
+func (e *Exporter) SetMermaidTheme(theme string) {
+	e.htmlRenderer.SetMermaidTheme(theme)
+}
+
+// SetPageOptions sets the paper size, orientation, margins and scale
+func (e *Exporter) SetPageOptions(page PageOptions) {
+	e.pdfGenerator.SetPageOptions(page)
+}
+
+// SetHeaderFooter sets the running header and footer of the pages. The
+// title and date default to the document's frontmatter, and the title to its
+// first H1 after that.
+func (e *Exporter) SetHeaderFooter(headerFooter HeaderFooterOptions) {
+	e.headerFooter = headerFooter
+}
+
+// StyleOptions selects the look of the exported document
+type StyleOptions struct {
+	Theme      string // Built-in theme: github, academic, dark or compact
+	CSS        string // Custom stylesheet, added after the theme
+	ReplaceCSS bool   // Use CSS instead of the built-in styles
+	Template   string // html/template for the whole HTML document
+
+	CodeStyle   string // Chroma style of code blocks ("" for the theme's)
+	LineNumbers bool   // Number the lines of code blocks
+}
+
+// SetStyle sets the theme, custom CSS and document template
+func (e *Exporter) SetStyle(style StyleOptions) error {
+	if err := e.htmlRenderer.SetTheme(style.Theme); err != nil {
+		return err
+	}
+	if err := e.htmlRenderer.SetCodeStyle(style.CodeStyle); err != nil {
+		return err
+	}
+	e.htmlRenderer.SetLineNumbers(style.LineNumbers)
+	e.htmlRenderer.SetCSS(style.CSS, style.ReplaceCSS)
+	return e.htmlRenderer.SetTemplate(style.Template)
+}
+
 // ExportToPDF converts markdown content to PDF and saves it to a file
 func (e *Exporter) ExportToPDF(markdown string, outputPath string) error {
 	// Convert markdown to HTML
@@ -29,6 +97,48 @@ func (e *Exporter) ExportToPDF(markdown string, outputPath string) error {
 		return fmt.Errorf("failed to render HTML: %w", err)
 	}
 
+	headerFooter := e.headerFooter
+	if headerFooter.Title == "" {
+		headerFooter.Title = e.htmlRenderer.FrontmatterValue("title")
+	}
+	if headerFooter.Title == "" {
+		for _, h := range renderer.ExtractHeadings(markdown) {
+			if h.Level == 1 {
+				headerFooter.Title = h.Text
+				break
+			}
+		}
+	}
+	if headerFooter.Date == "" {
+		headerFooter.Date = e.htmlRenderer.FrontmatterValue("date")
+	}
+	e.pdfGenerator.SetHeaderFooter(headerFooter)
+
+	return e.writePDF(html, outputPath)
+}
+
+// ExportBookToPDF renders the chapters of a book into one PDF. The header
+// and footer title defaults to the book's title.
+func (e *Exporter) ExportBookToPDF(book *renderer.Book, outputPath string) error {
+	html, err := e.htmlRenderer.RenderBookToHTML(book)
+	if err != nil {
+		return fmt.Errorf("failed to render HTML: %w", err)
+	}
+
+	headerFooter := e.headerFooter
+	if headerFooter.Title == "" {
+		headerFooter.Title = e.htmlRenderer.FrontmatterValue("title")
+	}
+	if headerFooter.Date == "" {
+		headerFooter.Date = e.htmlRenderer.FrontmatterValue("date")
+	}
+	e.pdfGenerator.SetHeaderFooter(headerFooter)
+
+	return e.writePDF(html, outputPath)
+}
+
+// writePDF prints an HTML document to a PDF file
+func (e *Exporter) writePDF(html string, outputPath string) error {
 	// Generate PDF from HTML
 	pdfBytes, err := e.pdfGenerator.GeneratePDF(html)
 	if err != nil {
@@ -51,5 +161,31 @@ func (e *Exporter) ExportFileToPDF(inputPath string, outputPath string) error {
 		return fmt.Errorf("failed to read input file: %w", err)
 	}
 
+	// Resolve wiki-links and note embeds relative to the input file
+	if inputPath != "" && inputPath != "-" {
+		if err := e.htmlRenderer.SetSourcePath(inputPath); err != nil {
+			return fmt.Errorf("failed to index Obsidian vault: %w", err)
+		}
+	}
+
+	if utils.IsCanvasFile(inputPath) {
+		return e.exportCanvasToPDF(content, outputPath)
+	}
+
 	return e.ExportToPDF(string(content), outputPath)
 }
+
+// exportCanvasToPDF renders an Obsidian canvas as an SVG and exports it
+func (e *Exporter) exportCanvasToPDF(content []byte, outputPath string) error {
+	canvas, err := renderer.ParseCanvas(content)
+	if err != nil {
+		return err
+	}
+
+	html, err := e.htmlRenderer.RenderCanvasToHTML(canvas)
+	if err != nil {
+		return fmt.Errorf("failed to render HTML: %w", err)
+	}
+
+	return e.writePDF(html, outputPath)
+}
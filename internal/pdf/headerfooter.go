@@ -0,0 +1,136 @@
+package pdf
+
+import (
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// HeaderFooterOptions configures the running header and footer printed on
+// every page. Header and Footer are html/template templates with these fields:
+//
+//	{{.Title}}       document title
+//	{{.Date}}        document date (the print date if unset)
+//	{{.Label}}       classification label, e.g. "CONFIDENTIAL"
+//	{{.Logo}}        the logo image
+//	{{.PageNumber}}  current page number
+//	{{.TotalPages}}  number of pages
+type HeaderFooterOptions struct {
+	Header      string // Header template ("" for the default)
+	Footer      string // Footer template ("" for the default)
+	Title       string // Document title
+	Date        string // Document date ("" for the print date)
+	Label       string // Classification label
+	Logo        string // Path of a logo image
+	PageNumbers bool   // Show "Page N of M" in the default footer
+}
+
+// Default header: logo and title on the left, label on the right
+const defaultHeaderTemplate = `<div style="display:flex;justify-content:space-between;align-items:center">` +
+	`<span>{{.Logo}} {{.Title}}</span><span style="font-weight:bold">{{.Label}}</span></div>`
+
+// Default footer: date, label and page numbers
+const defaultFooterTemplate = `<div style="display:flex;justify-content:space-between">` +
+	`<span>{{.Date}}</span><span style="font-weight:bold">{{.Label}}</span>` +
+	`<span>{{if .PageNumbers}}Page {{.PageNumber}} of {{.TotalPages}}{{end}}</span></div>`
+
+// minHeaderFooterMargin is the top and bottom margin, in inches, that leaves
+// room for the header and footer
+const minHeaderFooterMargin = 0.75
+
+// headerFooterData holds the values of the template fields
+type headerFooterData struct {
+	Title       string
+	Date        template.HTML
+	Label       string
+	Logo        template.HTML
+	PageNumber  template.HTML
+	TotalPages  template.HTML
+	PageNumbers bool
+}
+
+// Enabled reports whether a header and footer are printed
+func (o HeaderFooterOptions) Enabled() bool {
+	return o.Header != "" || o.Footer != "" || o.Label != "" || o.Logo != "" || o.PageNumbers
+}
+
+// Validate checks the templates and reads the logo
+func (o HeaderFooterOptions) Validate() error {
+	_, _, err := o.templates(DefaultPageOptions().Margins)
+	return err
+}
+
+// templates renders the header and footer templates for Chrome, padded to
+// line up with the page margins
+func (o HeaderFooterOptions) templates(margins Margins) (string, string, error) {
+	data := headerFooterData{
+		Title: o.Title,
+		Label: o.Label,
+		// Chrome fills in elements with these classes
+		Date:        `<span class="date"></span>`,
+		PageNumber:  `<span class="pageNumber"></span>`,
+		TotalPages:  `<span class="totalPages"></span>`,
+		PageNumbers: o.PageNumbers,
+	}
+	if o.Date != "" {
+		data.Date = template.HTML(template.HTMLEscapeString(o.Date))
+	}
+	if o.Logo != "" {
+		logo, err := logoImage(o.Logo)
+		if err != nil {
+			return "", "", err
+		}
+		data.Logo = logo
+	}
+
+	header, err := executeHeaderFooter("header", o.Header, defaultHeaderTemplate, data, margins)
+	if err != nil {
+		return "", "", err
+	}
+	footer, err := executeHeaderFooter("footer", o.Footer, defaultFooterTemplate, data, margins)
+	if err != nil {
+		return "", "", err
+	}
+	return header, footer, nil
+}
+
+// executeHeaderFooter renders a header or footer template. Chrome prints
+// them with a tiny default font and no padding, so the result is wrapped in
+// a block with a readable font size and the page's side margins.
+func executeHeaderFooter(name, text, fallback string, data headerFooterData, margins Margins) (string, error) {
+	if text == "" {
+		text = fallback
+	}
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid PDF %s template: %w", name, err)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<div style="width:100%%;box-sizing:border-box;padding:0 %.2fin 0 %.2fin;font-family:-apple-system,BlinkMacSystemFont,'Segoe UI',Helvetica,Arial,sans-serif;font-size:8pt;color:#555">`,
+		margins.Right, margins.Left)
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("invalid PDF %s template: %w", name, err)
+	}
+	b.WriteString("</div>")
+	return b.String(), nil
+}
+
+// logoImage reads an image file into an <img> tag. Chrome does not load
+// external resources in headers and footers, so it is a data URI.
+func logoImage(path string) (template.HTML, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read logo: %w", err)
+	}
+	mimeType := mime.TypeByExtension(strings.ToLower(filepath.Ext(path)))
+	if !strings.HasPrefix(mimeType, "image/") {
+		return "", fmt.Errorf("logo %s is not a PNG, JPEG, GIF, SVG or WebP image", path)
+	}
+	src := "data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(data)
+	return template.HTML(`<img src="` + src + `" style="height:0.3in;vertical-align:middle">`), nil
+}
@@ -0,0 +1,157 @@
+package pdf
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/chromedp/cdproto/emulation"
+	"github.com/chromedp/chromedp"
+)
+
+// tocPageNumbersScript fills in the page numbers of a printed table of
+// contents (nav.toc-print). The page is laid out at the printed width, and
+// the pages are cut from it the way Chrome paginates: at forced page breaks,
+// before elements kept together (break-inside: avoid) that would straddle a
+// page end, before headings that would end a page, and otherwise every page
+// height. %f is the printable page height in CSS pixels.
+const tocPageNumbersScript = `(() => {
+	const pageHeight = %f;
+	const breaks = [], keeps = [];
+	for (const el of document.body.querySelectorAll('*')) {
+		const style = getComputedStyle(el);
+		const rect = el.getBoundingClientRect();
+		const top = rect.top + window.scrollY, bottom = rect.bottom + window.scrollY;
+		if (style.breakBefore === 'page') breaks.push(top);
+		if (style.breakAfter === 'page') breaks.push(bottom);
+		let keepEnd = style.breakInside === 'avoid' || style.pageBreakInside === 'avoid' ? bottom : top;
+		const next = el.nextElementSibling;
+		if (style.breakAfter === 'avoid' && next) {
+			// A heading stays with the first lines that follow it
+			const nextRect = next.getBoundingClientRect();
+			keepEnd = Math.max(keepEnd, nextRect.top + window.scrollY + Math.min(nextRect.height, 48));
+		}
+		if (keepEnd > top && keepEnd - top <= pageHeight) keeps.push([top, keepEnd]);
+	}
+	breaks.sort((a, b) => a - b);
+	keeps.sort((a, b) => a[0] - b[0]);
+
+	const starts = [0];
+	const end = document.documentElement.scrollHeight;
+	for (let b = 0, k = 0; ;) {
+		const start = starts[starts.length - 1];
+		let next = start + pageHeight;
+		while (b < breaks.length && breaks[b] <= start + 1) b++;
+		if (b < breaks.length && breaks[b] < next) next = breaks[b];
+		while (k < keeps.length && keeps[k][0] <= start + 1) k++;
+		for (let i = k; i < keeps.length && keeps[i][0] < next; i++) {
+			if (keeps[i][1] > next) {
+				next = keeps[i][0];
+				break;
+			}
+		}
+		if (next >= end - 1) break;
+		starts.push(next);
+	}
+
+	const pageOf = (y) => starts.filter((start) => start <= y + 1).length;
+
+	for (const link of document.querySelectorAll('nav.toc-print a[href^="#"]')) {
+		const target = document.getElementById(decodeURIComponent(link.getAttribute('href').slice(1)));
+		const number = link.querySelector('.toc-page');
+		if (target && number) {
+			number.textContent = pageOf(target.getBoundingClientRect().top + window.scrollY);
+		}
+	}
+	return true;
+})()`
+
+// cssPageSizeScript returns the page size set by CSS @page rules as
+// [width, height] in inches, [0, 0] if only an orientation is set, or null.
+// The orientation is the third element ("", "portrait" or "landscape").
+const cssPageSizeScript = `(() => {
+	const named = {
+		a5: [148, 210], a4: [210, 297], a3: [297, 420], b5: [176, 250], b4: [250, 353],
+		'jis-b5': [182, 257], 'jis-b4': [257, 364],
+		letter: [215.9, 279.4], legal: [215.9, 355.6], ledger: [279.4, 431.8]
+	};
+	let size = '';
+	const scan = (rules) => {
+		for (const rule of rules) {
+			if (rule instanceof CSSPageRule && rule.selectorText === '' && rule.style.getPropertyValue('size')) {
+				size = rule.style.getPropertyValue('size');
+			} else if (rule instanceof CSSMediaRule && rule.media.mediaText.match(/^(|all|print)$/)) {
+				scan(rule.cssRules);
+			}
+		}
+	};
+	for (const sheet of document.styleSheets) {
+		try { scan(sheet.cssRules); } catch (e) {}
+	}
+	if (size === '' || size === 'auto') return null;
+
+	const probe = document.createElement('div');
+	probe.style.position = 'absolute';
+	document.body.appendChild(probe);
+	const inches = (length) => {
+		probe.style.width = length;
+		return probe.getBoundingClientRect().width / 96;
+	};
+	let width = 0, height = 0, orientation = '';
+	const lengths = [];
+	for (const word of size.trim().split(/\s+/)) {
+		if (word === 'portrait' || word === 'landscape') orientation = word;
+		else if (named[word.toLowerCase()]) [width, height] = named[word.toLowerCase()].map((mm) => inches(mm + 'mm'));
+		else lengths.push(inches(word));
+	}
+	probe.remove();
+	if (lengths.length > 0) [width, height] = [lengths[0], lengths[lengths.length - 1]];
+	if (orientation === 'landscape' && width < height) [width, height] = [height, width];
+	if (orientation === 'portrait' && width > height) [width, height] = [height, width];
+	return [width, height, orientation];
+})()`
+
+// hasPrintTOC reports whether an HTML document has a printed table of
+// contents whose page numbers must be filled in
+func hasPrintTOC(html string) bool {
+	return strings.Contains(html, `class="toc toc-print"`)
+}
+
+// fillTOCPageNumbers lays the page out like the printed page (print media,
+// printable width) and fills in the page numbers of the printed table of
+// contents. Sizes are in inches; a CSS @page size replaces the paper size when
+// preferCSSPageSize is set, as it does for the PDF.
+func fillTOCPageNumbers(paperWidth, paperHeight float64, margins Margins, scale float64, preferCSSPageSize bool) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		if err := emulation.SetEmulatedMedia().WithMedia("print").Do(ctx); err != nil {
+			return err
+		}
+
+		width, height := paperWidth, paperHeight
+		if preferCSSPageSize {
+			var size []any
+			if err := chromedp.Evaluate(cssPageSizeScript, &size).Do(ctx); err != nil {
+				return err
+			}
+			if len(size) == 3 {
+				w, _ := size[0].(float64)
+				h, _ := size[1].(float64)
+				if w > 0 && h > 0 {
+					width, height = w, h
+				} else if orientation, _ := size[2].(string); orientation != "" && (orientation == "landscape") != (width > height) {
+					width, height = height, width
+				}
+			}
+		}
+
+		// CSS pixels are 1/96 inch; scaled content has room for more of them
+		cssWidth := (width - margins.Left - margins.Right) * 96 / scale
+		cssHeight := (height - margins.Top - margins.Bottom) * 96 / scale
+		err := emulation.SetDeviceMetricsOverride(int64(math.Round(cssWidth)), int64(math.Round(cssHeight)), 1, false).Do(ctx)
+		if err != nil {
+			return err
+		}
+		return chromedp.Evaluate(fmt.Sprintf(tocPageNumbersScript, cssHeight), nil).Do(ctx)
+	})
+}
@@ -8,19 +8,26 @@ type BlockType int
 const (
 	BlockTypeMermaid BlockType = iota
 	BlockTypeImage
+	BlockTypeCallout
 )
 
-// ContentBlock represents any block of special content (Mermaid or Image)
+// ContentBlock represents any block of special content (Mermaid, Image or Callout)
 type ContentBlock struct {
 	Type      BlockType
 	Mermaid   *MermaidBlock // Non-nil if Type == BlockTypeMermaid
 	Image     *ImageBlock   // Non-nil if Type == BlockTypeImage
+	Callout   *CalloutBlock // Non-nil if Type == BlockTypeCallout
 	StartLine int           // 1-indexed line number
 	EndLine   int           // 1-indexed line number (exclusive for next segment)
 }
 
 // DetectContentBlocks finds all special content blocks (images and mermaid) in markdown
 func DetectContentBlocks(content string) []ContentBlock {
+	return detectContentBlocks(content, false)
+}
+
+// detectContentBlocks finds content blocks, optionally including remote images
+func detectContentBlocks(content string, includeRemote bool) []ContentBlock {
 	var blocks []ContentBlock
 	
 	// Detect Mermaid blocks
@@ -35,7 +42,7 @@ func DetectContentBlocks(content string) []ContentBlock {
 	}
 	
 	// Detect image blocks
-	imageBlocks := DetectImageBlocks(content)
+	imageBlocks := detectImageBlocks(content, includeRemote)
 	for i := range imageBlocks {
 		blocks = append(blocks, ContentBlock{
 			Type:      BlockTypeImage,
@@ -45,6 +52,27 @@ func DetectContentBlocks(content string) []ContentBlock {
 		})
 	}
 	
+	// Detect callouts. Images and diagrams inside a callout are rendered as
+	// part of the callout's body, so they are dropped as separate blocks.
+	calloutBlocks := DetectCalloutBlocks(content)
+	if len(calloutBlocks) > 0 {
+		var outside []ContentBlock
+		for _, block := range blocks {
+			if !insideCallout(block, calloutBlocks) {
+				outside = append(outside, block)
+			}
+		}
+		blocks = outside
+	}
+	for i := range calloutBlocks {
+		blocks = append(blocks, ContentBlock{
+			Type:      BlockTypeCallout,
+			Callout:   &calloutBlocks[i],
+			StartLine: calloutBlocks[i].StartLine,
+			EndLine:   calloutBlocks[i].EndLine,
+		})
+	}
+	
 	// Sort by start line
 	sort.Slice(blocks, func(i, j int) bool {
 		return blocks[i].StartLine < blocks[j].StartLine
@@ -52,3 +80,13 @@ func DetectContentBlocks(content string) []ContentBlock {
 	
 	return blocks
 }
+
+// insideCallout reports whether a block lies within one of the callouts
+func insideCallout(block ContentBlock, callouts []CalloutBlock) bool {
+	for _, callout := range callouts {
+		if block.StartLine >= callout.StartLine && block.EndLine <= callout.EndLine {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,248 @@
+package renderer
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/aquele_dinho/mdviewer/internal/utils"
+	"github.com/yuin/goldmark/ast"
+	"gopkg.in/yaml.v3"
+)
+
+// Book is a set of markdown files exported as one document. It is read from
+// a list of files, a SUMMARY.md or a YAML manifest:
+//
+//	title: Onboarding handbook
+//	author: Platform team
+//	chapters:
+//	  - welcome.md
+//	  - setup/laptop.md
+type Book struct {
+	Title    string   `yaml:"title"`
+	Author   string   `yaml:"author"`
+	Date     string   `yaml:"date"`
+	Chapters []string `yaml:"chapters"` // Chapter files, relative to the manifest
+}
+
+// summaryLinkRegexp matches the chapter links of a SUMMARY.md, in list items
+// ("- [Setup](setup.md)") or on their own line
+var summaryLinkRegexp = regexp.MustCompile(`^\s*(?:[-*+]\s+|\d+\.\s+)?\[([^\]]*)\]\(([^)]*)\)\s*$`)
+
+// ReadBook builds a book from the input files: a single .yaml/.yml manifest,
+// a single SUMMARY.md, or the chapter files in order
+func ReadBook(paths []string) (*Book, error) {
+	if len(paths) == 1 {
+		switch ext := strings.ToLower(filepath.Ext(paths[0])); {
+		case ext == ".yaml" || ext == ".yml":
+			return readBookManifest(paths[0])
+		case strings.EqualFold(filepath.Base(paths[0]), "SUMMARY.md"):
+			return readBookSummary(paths[0])
+		}
+	}
+	return &Book{Chapters: paths}, nil
+}
+
+// readBookManifest reads a YAML book manifest
+func readBookManifest(path string) (*Book, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read book manifest: %w", err)
+	}
+	var book Book
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&book); err != nil {
+		return nil, fmt.Errorf("invalid book manifest %s: %w", path, err)
+	}
+	if len(book.Chapters) == 0 {
+		return nil, fmt.Errorf("book manifest %s has no chapters", path)
+	}
+
+	dir := filepath.Dir(path)
+	for i, chapter := range book.Chapters {
+		if !filepath.IsAbs(chapter) {
+			book.Chapters[i] = filepath.Join(dir, chapter)
+		}
+	}
+	return &book, nil
+}
+
+// readBookSummary reads the chapters of a SUMMARY.md (as used by mdBook and
+// GitBook) in the order they are linked. Its H1 is the book title, unless it
+// is just "Summary".
+func readBookSummary(path string) (*Book, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read book summary: %w", err)
+	}
+
+	book := &Book{}
+	dir := filepath.Dir(path)
+	for _, line := range strings.Split(string(data), "\n") {
+		if title, ok := strings.CutPrefix(strings.TrimSpace(line), "# "); ok && book.Title == "" {
+			if title = strings.TrimSpace(title); !strings.EqualFold(title, "summary") {
+				book.Title = title
+			}
+			continue
+		}
+
+		m := summaryLinkRegexp.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		// Draft chapters have no file, and remote links are not chapters
+		target, _, _ := strings.Cut(m[2], "#")
+		if target == "" || strings.Contains(target, "://") {
+			continue
+		}
+		if unescaped, err := url.PathUnescape(target); err == nil {
+			target = unescaped
+		}
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(dir, target)
+		}
+		book.Chapters = append(book.Chapters, target)
+	}
+
+	if len(book.Chapters) == 0 {
+		return nil, fmt.Errorf("book summary %s links no chapters", path)
+	}
+	return book, nil
+}
+
+// bookChapter is a parsed chapter of a book
+type bookChapter struct {
+	path     string // Absolute path of the file
+	id       string // ID of the chapter's <section>
+	document *htmlDocument
+}
+
+// RenderBookToHTML renders the chapters of a book into one HTML document.
+// Every chapter starts on a new page, links between chapters point at the
+// chapter or heading within the document, and heading and footnote IDs are
+// unique across chapters, so the PDF gets one outline.
+func (r *HTMLRenderer) RenderBookToHTML(book *Book) (string, error) {
+	// Heading IDs are unique across all chapters
+	r.ids = newHeadingIDs()
+	defer func() {
+		r.ids, r.footnotePrefix = nil, ""
+	}()
+
+	title := book.Title
+	chapters := make([]*bookChapter, len(book.Chapters))
+	var headings []Heading
+	for i, path := range book.Chapters {
+		content, err := utils.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read chapter: %w", err)
+		}
+		if err := r.SetSourcePath(path); err != nil {
+			return "", fmt.Errorf("failed to index Obsidian vault: %w", err)
+		}
+		d, err := r.parseDocument(string(content))
+		if err != nil {
+			return "", fmt.Errorf("failed to render %s: %w", path, err)
+		}
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return "", err
+		}
+		chapters[i] = &bookChapter{path: abs, id: fmt.Sprintf("chapter-%d", i+1), document: d}
+		headings = append(headings, d.headings...)
+
+		// Untitled books are named after their first chapter
+		if i == 0 && title == "" {
+			title = r.frontmatter.String("title")
+			for _, h := range d.headings {
+				if title == "" && h.Level == 1 {
+					title = h.Text
+				}
+			}
+		}
+	}
+
+	var b strings.Builder
+	if r.printTOC {
+		b.WriteString(tocHTML(tocHeadings(headings), true))
+	}
+	for _, chapter := range chapters {
+		resolveChapterLinks(chapter, chapters)
+		r.footnotePrefix = chapter.id + "-"
+		content, err := r.renderDocument(chapter.document)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "<section class=\"chapter\" id=\"%s\">\n%s</section>\n", chapter.id, content)
+	}
+
+	// The book's metadata names the document
+	r.frontmatter = &Frontmatter{Data: make(map[string]any)}
+	for _, field := range [][2]string{{"title", title}, {"author", book.Author}, {"date", book.Date}} {
+		if field[1] != "" {
+			r.frontmatter.Data[field[0]] = field[1]
+			r.frontmatter.Keys = append(r.frontmatter.Keys, field[0])
+		}
+	}
+
+	return r.wrapHTML(b.String(), tocHTML(tocHeadings(headings), false))
+}
+
+// resolveChapterLinks points links to other chapters of the book, such as
+// [Setup](setup.md#install) or [[Setup#Install]], at the heading within the
+// book, or at the start of the chapter
+func resolveChapterLinks(chapter *bookChapter, chapters []*bookChapter) {
+	_ = ast.Walk(chapter.document.doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		link, ok := n.(*ast.Link)
+		if !entering || !ok {
+			return ast.WalkContinue, nil
+		}
+		dest := string(link.Destination)
+		if dest == "" || strings.HasPrefix(dest, "#") || strings.Contains(dest, ":") {
+			return ast.WalkContinue, nil
+		}
+
+		path, fragment, _ := strings.Cut(dest, "#")
+		if unescaped, err := url.PathUnescape(path); err == nil {
+			path = unescaped
+		}
+		path = filepath.FromSlash(path)
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(chapter.path), path)
+		}
+
+		target := findChapter(path, chapters)
+		if target == nil {
+			return ast.WalkContinue, nil
+		}
+		link.Destination = []byte("#" + target.id)
+		if h, ok := findHeading(fragment, target.document.headings); ok && fragment != "" {
+			link.Destination = []byte("#" + h.ID)
+		}
+		return ast.WalkContinue, nil
+	})
+}
+
+// findChapter returns the chapter at path, or nil. Outside a vault, wiki
+// links to notes in other folders point next to the linking file, so a
+// chapter with the same file name is found too, if there is just one.
+func findChapter(path string, chapters []*bookChapter) *bookChapter {
+	var named []*bookChapter
+	name := strings.TrimSuffix(filepath.Base(path), ".md")
+	for _, chapter := range chapters {
+		if chapter.path == path || chapter.path == path+".md" {
+			return chapter
+		}
+		if strings.EqualFold(strings.TrimSuffix(filepath.Base(chapter.path), ".md"), name) {
+			named = append(named, chapter)
+		}
+	}
+	if len(named) == 1 {
+		return named[0]
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package renderer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadBook(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		title    string
+		chapters []string // Relative to the book's directory
+		err      string
+	}{
+		{
+			name:     "manifest",
+			file:     "book.yaml",
+			content:  "title: Handbook\nauthor: Team\nchapters:\n  - intro.md\n  - setup/laptop.md\n",
+			title:    "Handbook",
+			chapters: []string{"intro.md", "setup/laptop.md"},
+		},
+		{
+			name:    "manifest without chapters",
+			file:    "book.yml",
+			content: "title: Handbook\n",
+			err:     "has no chapters",
+		},
+		{
+			name:    "manifest with unknown key",
+			file:    "book.yaml",
+			content: "title: Handbook\nchapter: [intro.md]\n",
+			err:     "field chapter not found",
+		},
+		{
+			name: "summary",
+			file: "SUMMARY.md",
+			content: "# Handbook\n\n[Preface](preface.md)\n\n- [Intro](intro.md)\n  - [Laptop](setup/My%20Laptop.md#top)\n" +
+				"1. [Usage](usage.md)\n- [Draft]()\n- [Site](https://example.com)\n\nSee [other](other.md) inline.\n",
+			title:    "Handbook",
+			chapters: []string{"preface.md", "intro.md", "setup/My Laptop.md", "usage.md"},
+		},
+		{
+			name:     "summary titled Summary",
+			file:     "summary.md",
+			content:  "# Summary\n\n- [Intro](intro.md)\n",
+			chapters: []string{"intro.md"},
+		},
+		{
+			name:    "summary without chapters",
+			file:    "SUMMARY.md",
+			content: "# Summary\n\n- [Site](https://example.com)\n",
+			err:     "links no chapters",
+		},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		path := filepath.Join(dir, tt.file)
+		if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		book, err := ReadBook([]string{path})
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s: err = %v, want %q", tt.name, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if book.Title != tt.title {
+			t.Errorf("%s: title = %q, want %q", tt.name, book.Title, tt.title)
+		}
+		var want []string
+		for _, chapter := range tt.chapters {
+			want = append(want, filepath.Join(dir, filepath.FromSlash(chapter)))
+		}
+		if strings.Join(book.Chapters, "\n") != strings.Join(want, "\n") {
+			t.Errorf("%s: chapters = %q, want %q", tt.name, book.Chapters, want)
+		}
+	}
+}
+
+func TestReadBookFileList(t *testing.T) {
+	paths := []string{"b.md", "a.md"}
+	book, err := ReadBook(paths)
+	if err != nil {
+		t.Fatalf("ReadBook: %v", err)
+	}
+	if strings.Join(book.Chapters, ",") != "b.md,a.md" {
+		t.Errorf("chapters = %q, want the files in order", book.Chapters)
+	}
+
+	// A single markdown file that is not a SUMMARY.md is one chapter
+	book, err = ReadBook([]string{"notes.md"})
+	if err != nil || len(book.Chapters) != 1 {
+		t.Errorf("ReadBook(notes.md) = %v, %v, want one chapter", book, err)
+	}
+}
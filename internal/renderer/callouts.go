@@ -0,0 +1,321 @@
+package renderer
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// CalloutBlock represents an Obsidian callout or GitHub alert:
+//
+//	> [!warning]- Optional title
+//	> Body text
+type CalloutBlock struct {
+	Type      string // Normalized callout type: "note", "warning", ...
+	Title     string // Custom title, or the default title for the type
+	Foldable  bool   // Declared with + or - after the type
+	Collapsed bool   // Declared with - (folded by default)
+	Content   string // Body markdown with one level of "> " removed
+	StartLine int    // 1-indexed line of the [!type] header
+	EndLine   int    // 1-indexed last line of the callout
+}
+
+// calloutStyle describes how a callout type is drawn
+type calloutStyle struct {
+	Icon      string // Emoji shown before the title
+	Color     string // ANSI 256 color for the terminal box
+	CSSColor  string // Accent color in HTML/PDF output
+	Canonical string // Type the style belongs to (for aliases)
+}
+
+// calloutStyles maps Obsidian callout types (and their aliases) and GitHub
+// alert types to their styles
+var calloutStyles = map[string]calloutStyle{
+	"note":      {"📝", "33", "#0969da", "note"},
+	"abstract":  {"📋", "44", "#00a3a3", "abstract"},
+	"summary":   {"📋", "44", "#00a3a3", "abstract"},
+	"tldr":      {"📋", "44", "#00a3a3", "abstract"},
+	"info":      {"ℹ️", "39", "#086ddd", "info"},
+	"todo":      {"☑️", "39", "#086ddd", "todo"},
+	"tip":       {"💡", "36", "#1a7f37", "tip"},
+	"hint":      {"💡", "36", "#1a7f37", "tip"},
+	"important": {"❗", "135", "#8250df", "important"},
+	"success":   {"✅", "34", "#08b94e", "success"},
+	"check":     {"✅", "34", "#08b94e", "success"},
+	"done":      {"✅", "34", "#08b94e", "success"},
+	"question":  {"❓", "214", "#ec7500", "question"},
+	"help":      {"❓", "214", "#ec7500", "question"},
+	"faq":       {"❓", "214", "#ec7500", "question"},
+	"warning":   {"⚠️", "214", "#9a6700", "warning"},
+	"attention": {"⚠️", "214", "#9a6700", "warning"},
+	"caution":   {"🛑", "196", "#cf222e", "caution"},
+	"failure":   {"❌", "196", "#e93147", "failure"},
+	"fail":      {"❌", "196", "#e93147", "failure"},
+	"missing":   {"❌", "196", "#e93147", "failure"},
+	"danger":    {"⚡", "196", "#e93147", "danger"},
+	"error":     {"⚡", "196", "#e93147", "danger"},
+	"bug":       {"🐞", "196", "#e93147", "bug"},
+	"example":   {"🧪", "99", "#7852ee", "example"},
+	"quote":     {"💬", "245", "#6e7781", "quote"},
+	"cite":      {"💬", "245", "#6e7781", "quote"},
+}
+
+var (
+	// Callout marker: [!type] / [!type]+ Title / [!type]- Title
+	calloutMarkerRegex = regexp.MustCompile(`^\[!([A-Za-z0-9_-]+)\]([+-]?)\s*(.*)$`)
+	// Callout header: the marker at the start of a blockquote
+	calloutHeaderRegex = regexp.MustCompile(`^\s*>\s*\[!([A-Za-z0-9_-]+)\]([+-]?)\s*(.*)$`)
+)
+
+// lookupCalloutStyle returns the style for a callout type; unknown types are
+// drawn like notes, as in Obsidian
+func lookupCalloutStyle(calloutType string) calloutStyle {
+	if style, ok := calloutStyles[calloutType]; ok {
+		return style
+	}
+	return calloutStyles["note"]
+}
+
+// DetectCalloutBlocks finds all callouts outside fenced code blocks
+func DetectCalloutBlocks(content string) []CalloutBlock {
+	var blocks []CalloutBlock
+	lines := strings.Split(content, "\n")
+	inCodeFence := false
+
+	for i := 0; i < len(lines); i++ {
+		if strings.HasPrefix(strings.TrimSpace(lines[i]), "```") {
+			inCodeFence = !inCodeFence
+			continue
+		}
+		if inCodeFence {
+			continue
+		}
+
+		m := calloutHeaderRegex.FindStringSubmatch(lines[i])
+		if m == nil {
+			continue
+		}
+
+		// The callout continues while lines are quoted
+		end := i
+		for end+1 < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[end+1]), ">") {
+			end++
+		}
+
+		var body []string
+		for _, line := range lines[i+1 : end+1] {
+			body = append(body, unquoteLine(line))
+		}
+
+		calloutType := strings.ToLower(m[1])
+		title := strings.TrimSpace(m[3])
+		if title == "" {
+			title = strings.ToUpper(calloutType[:1]) + calloutType[1:]
+		}
+
+		blocks = append(blocks, CalloutBlock{
+			Type:      calloutType,
+			Title:     title,
+			Foldable:  m[2] != "",
+			Collapsed: m[2] == "-",
+			Content:   strings.Join(body, "\n"),
+			StartLine: i + 1,
+			EndLine:   end + 1,
+		})
+		i = end
+	}
+
+	return blocks
+}
+
+// unquoteLine removes one level of blockquote marker from a line
+func unquoteLine(line string) string {
+	trimmed := strings.TrimLeft(line, " \t")
+	trimmed = strings.TrimPrefix(trimmed, ">")
+	return strings.TrimPrefix(trimmed, " ")
+}
+
+// PreprocessCallouts rewrites callout headers into plain blockquotes with an
+// icon and bold title. It is the fallback for output that is rendered by
+// Glamour alone (callouts detected by the viewer are drawn as boxes instead).
+func PreprocessCallouts(content string) string {
+	lines := strings.Split(content, "\n")
+	var out []string
+	inCodeFence := false
+
+	for _, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(strings.TrimLeft(line, "> ")), "```") {
+			inCodeFence = !inCodeFence
+			out = append(out, line)
+			continue
+		}
+		if inCodeFence {
+			out = append(out, line)
+			continue
+		}
+
+		// Count the quote markers so nested callouts keep their outer ones
+		depth := 0
+		rest := strings.TrimLeft(line, " \t")
+		for strings.HasPrefix(rest, ">") {
+			depth++
+			rest = strings.TrimLeft(rest[1:], " ")
+		}
+
+		m := calloutMarkerRegex.FindStringSubmatch(rest)
+		if depth == 0 || m == nil {
+			out = append(out, line)
+			continue
+		}
+		calloutType := strings.ToLower(m[1])
+		title := strings.TrimSpace(m[3])
+		if title == "" {
+			title = strings.ToUpper(calloutType[:1]) + calloutType[1:]
+		}
+		style := lookupCalloutStyle(calloutType)
+		// The empty quoted line keeps the title in its own paragraph
+		prefix := strings.TrimSpace(strings.Repeat("> ", depth))
+		out = append(out, prefix+" **"+style.Icon+" "+title+"**", prefix)
+	}
+
+	return strings.Join(out, "\n")
+}
+
+// RenderCallout draws a callout as a colored box with an icon and title,
+// rendering its body with Glamour. Collapsed callouts show only their title,
+// as in Obsidian.
+func (r *Renderer) RenderCallout(block CalloutBlock) (string, error) {
+	style := lookupCalloutStyle(block.Type)
+	color := lipgloss.Color(style.Color)
+
+	// Leave room for the left margin, border and padding
+	boxWidth := r.options.Width - 4
+	if boxWidth < 24 {
+		boxWidth = 24
+	}
+	innerWidth := boxWidth - 2
+
+	title := style.Icon + " " + block.Title
+	if block.Foldable {
+		marker := "▼"
+		if block.Collapsed {
+			marker = "▶"
+		}
+		title = marker + " " + title
+	}
+
+	var body string
+	if strings.TrimSpace(block.Content) != "" && !block.Collapsed {
+		glamourRenderer, err := newGlamourRenderer(r.options.Style, innerWidth)
+		if err != nil {
+			return "", fmt.Errorf("failed to create callout renderer: %w", err)
+		}
+		body, err = glamourRenderer.Render(PreprocessCallouts(block.Content))
+		if err != nil {
+			return "", fmt.Errorf("failed to render callout: %w", err)
+		}
+		body = trimRenderedBlock(applyHyperlinks(applyInlineStyles(body), r.links))
+	}
+
+	content := lipgloss.NewStyle().Bold(true).Foreground(color).Render(title)
+	if body != "" {
+		content += "\n" + body
+	}
+
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(color).
+		Padding(0, 1).
+		Width(boxWidth).
+		MarginLeft(2).
+		Render(content)
+
+	return box + "\n", nil
+}
+
+// trimRenderedBlock strips the blank lines, left margin and trailing padding
+// Glamour adds around a document so it can be embedded in a box. Lines are
+// measured without their ANSI escape codes.
+func trimRenderedBlock(rendered string) string {
+	lines := strings.Split(rendered, "\n")
+
+	// Find the common indentation of non-empty lines (the document margin)
+	indent := -1
+	for _, line := range lines {
+		plain := ansi.Strip(line)
+		if strings.TrimSpace(plain) == "" {
+			continue
+		}
+		n := len(plain) - len(strings.TrimLeft(plain, " "))
+		if indent == -1 || n < indent {
+			indent = n
+		}
+	}
+
+	var out []string
+	for _, line := range lines {
+		plain := strings.TrimRight(ansi.Strip(line), " ")
+		if strings.TrimSpace(plain) == "" {
+			out = append(out, "")
+			continue
+		}
+		// Cut trailing padding, then the left margin
+		line = ansi.Truncate(line, ansi.StringWidth(plain), "")
+		if indent > 0 {
+			line = ansi.TruncateLeft(line, indent, "")
+		}
+		out = append(out, line)
+	}
+
+	return strings.Trim(strings.Join(out, "\n"), "\n")
+}
+
+// calloutsToHTML converts callouts into styled HTML containers for the PDF
+// renderer. Foldable callouts become <details> elements, open unless they
+// are collapsed; the print stylesheet shows collapsed ones in PDFs. The body
+// stays markdown and is converted recursively, so nested callouts work too.
+func calloutsToHTML(content string) string {
+	blocks := DetectCalloutBlocks(content)
+	if len(blocks) == 0 {
+		return content
+	}
+
+	lines := strings.Split(content, "\n")
+	var out []string
+	curr := 0
+	for _, block := range blocks {
+		out = append(out, lines[curr:block.StartLine-1]...)
+
+		style := lookupCalloutStyle(block.Type)
+		title := style.Icon + " " + html.EscapeString(block.Title)
+		attrs := fmt.Sprintf(`class="callout callout-%s" data-callout="%s" style="--callout-color: %s"`,
+			style.Canonical, html.EscapeString(block.Type), style.CSSColor)
+
+		out = append(out, "")
+		if block.Foldable {
+			open := " open"
+			if block.Collapsed {
+				open = ""
+			}
+			out = append(out, "<details "+attrs+open+">", `<summary class="callout-title">`+title+`</summary>`)
+		} else {
+			out = append(out, "<div "+attrs+">", `<div class="callout-title">`+title+`</div>`)
+		}
+		out = append(out, `<div class="callout-content">`, "", calloutsToHTML(block.Content), "", "</div>")
+		if block.Foldable {
+			out = append(out, "</details>")
+		} else {
+			out = append(out, "</div>")
+		}
+		out = append(out, "")
+
+		curr = block.EndLine
+	}
+	out = append(out, lines[curr:]...)
+
+	return strings.Join(out, "\n")
+}
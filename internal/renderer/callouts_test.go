@@ -0,0 +1,119 @@
+package renderer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestDetectCalloutBlocks(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []CalloutBlock
+	}{
+		{
+			name:    "default title",
+			content: "> [!note]\n> Body",
+			want:    []CalloutBlock{{Type: "note", Title: "Note", Content: "Body", StartLine: 1, EndLine: 2}},
+		},
+		{
+			name:    "custom title and type case",
+			content: "> [!WARNING] Mind the gap\n> Body",
+			want:    []CalloutBlock{{Type: "warning", Title: "Mind the gap", Content: "Body", StartLine: 1, EndLine: 2}},
+		},
+		{
+			name:    "expanded",
+			content: "> [!tip]+ Open",
+			want:    []CalloutBlock{{Type: "tip", Title: "Open", Foldable: true, StartLine: 1, EndLine: 1}},
+		},
+		{
+			name:    "collapsed",
+			content: "> [!faq]- Closed\n>\n> Answer",
+			want:    []CalloutBlock{{Type: "faq", Title: "Closed", Foldable: true, Collapsed: true, Content: "\nAnswer", StartLine: 1, EndLine: 3}},
+		},
+		{
+			name:    "ends at unquoted line",
+			content: "text\n  >[!bug] Indented\n> Body\nafter",
+			want:    []CalloutBlock{{Type: "bug", Title: "Indented", Content: "Body", StartLine: 2, EndLine: 3}},
+		},
+		{
+			name:    "nested callout stays in the body",
+			content: "> [!note]\n> > [!tip]\n> > Inner",
+			want:    []CalloutBlock{{Type: "note", Title: "Note", Content: "> [!tip]\n> Inner", StartLine: 1, EndLine: 3}},
+		},
+		{
+			name:    "code fence",
+			content: "```\n> [!note]\n```",
+		},
+		{
+			name:    "plain blockquote",
+			content: "> [note] not a callout",
+		},
+		{
+			name:    "marker not at the start",
+			content: "> see [!note]",
+		},
+	}
+	for _, tt := range tests {
+		got := DetectCalloutBlocks(tt.content)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: got %d callouts, want %d", tt.name, len(got), len(tt.want))
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: callout %d = %+v, want %+v", tt.name, i, got[i], tt.want[i])
+			}
+		}
+	}
+}
+
+func TestPreprocessCallouts(t *testing.T) {
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{"> [!note]\n> Body", "> **📝 Note**\n>\n> Body"},
+		{"> [!tip]- Hidden", "> **💡 Hidden**\n>"},
+		{"> [!unknown] Custom", "> **📝 Custom**\n>"},
+		{"> > [!warning]", "> > **⚠️ Warning**\n> >"},
+		{"```\n> [!note]\n```", "```\n> [!note]\n```"},
+		{"[!note] unquoted", "[!note] unquoted"},
+	}
+	for _, tt := range tests {
+		if got := PreprocessCallouts(tt.content); got != tt.want {
+			t.Errorf("PreprocessCallouts(%q) = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestCalloutFolding(t *testing.T) {
+	tests := []struct {
+		content string
+		tag     string // Opening tag in HTML
+		body    bool   // Body shown in the terminal
+	}{
+		{"> [!note] Title\n> Secret body", `<div class="callout callout-note" data-callout="note" style="--callout-color: #0969da">`, true},
+		{"> [!note]+ Title\n> Secret body", `<details class="callout callout-note" data-callout="note" style="--callout-color: #0969da" open>`, true},
+		{"> [!note]- Title\n> Secret body", `<details class="callout callout-note" data-callout="note" style="--callout-color: #0969da">`, false},
+	}
+	r, err := NewRenderer(RenderOptions{Style: "dark", Width: 80})
+	if err != nil {
+		t.Fatalf("NewRenderer: %v", err)
+	}
+	for _, tt := range tests {
+		if got := calloutsToHTML(tt.content); !strings.Contains(got, tt.tag+"\n") {
+			t.Errorf("calloutsToHTML(%q) does not contain %q:\n%s", tt.content, tt.tag, got)
+		}
+
+		box, err := r.RenderCallout(DetectCalloutBlocks(tt.content)[0])
+		if err != nil {
+			t.Fatalf("RenderCallout: %v", err)
+		}
+		if body := strings.Contains(ansi.Strip(box), "Secret body"); body != tt.body {
+			t.Errorf("RenderCallout(%q) shows the body = %v, want %v", tt.content, body, tt.body)
+		}
+	}
+}
@@ -0,0 +1,609 @@
+package renderer
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Canvas is an Obsidian canvas (.canvas file, JSON Canvas format)
+type Canvas struct {
+	Nodes []CanvasNode `json:"nodes"`
+	Edges []CanvasEdge `json:"edges"`
+}
+
+// CanvasNode is a card on a canvas: a text card, a file, a link or a group
+type CanvasNode struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"` // "text", "file", "link" or "group"
+	X       int    `json:"x"`
+	Y       int    `json:"y"`
+	Width   int    `json:"width"`
+	Height  int    `json:"height"`
+	Color   string `json:"color,omitempty"`   // Preset "1"-"6" or "#rrggbb"
+	Text    string `json:"text,omitempty"`    // Markdown of text nodes
+	File    string `json:"file,omitempty"`    // Vault path of file nodes
+	Subpath string `json:"subpath,omitempty"` // "#Heading" within a file node
+	URL     string `json:"url,omitempty"`     // Target of link nodes
+	Label   string `json:"label,omitempty"`   // Title of group nodes
+}
+
+// CanvasEdge is an arrow between two nodes
+type CanvasEdge struct {
+	ID       string `json:"id"`
+	FromNode string `json:"fromNode"`
+	FromSide string `json:"fromSide,omitempty"` // "top", "right", "bottom" or "left"
+	FromEnd  string `json:"fromEnd,omitempty"`  // "none" (default) or "arrow"
+	ToNode   string `json:"toNode"`
+	ToSide   string `json:"toSide,omitempty"`
+	ToEnd    string `json:"toEnd,omitempty"` // "arrow" (default) or "none"
+	Color    string `json:"color,omitempty"`
+	Label    string `json:"label,omitempty"`
+}
+
+// canvasColor is a canvas color preset in the terminal and in HTML
+type canvasColor struct {
+	ANSI string // ANSI 256 color
+	CSS  string
+}
+
+// canvasColors maps Obsidian's color presets; "" is the default gray
+var canvasColors = map[string]canvasColor{
+	"":  {"245", "#7e7e7e"},
+	"1": {"196", "#e93147"},
+	"2": {"208", "#ec7500"},
+	"3": {"220", "#e0ac00"},
+	"4": {"34", "#08b94e"},
+	"5": {"44", "#00bfbc"},
+	"6": {"135", "#7852ee"},
+}
+
+// ParseCanvas parses the JSON of a .canvas file
+func ParseCanvas(data []byte) (*Canvas, error) {
+	var canvas Canvas
+	if err := json.Unmarshal(data, &canvas); err != nil {
+		return nil, fmt.Errorf("failed to parse canvas: %w", err)
+	}
+	return &canvas, nil
+}
+
+// node returns the node with the given ID
+func (c *Canvas) node(id string) (CanvasNode, bool) {
+	for _, n := range c.Nodes {
+		if n.ID == id {
+			return n, true
+		}
+	}
+	return CanvasNode{}, false
+}
+
+// bounds returns the bounding box of all nodes
+func (c *Canvas) bounds() (minX, minY, maxX, maxY int) {
+	for i, n := range c.Nodes {
+		if i == 0 || n.X < minX {
+			minX = n.X
+		}
+		if i == 0 || n.Y < minY {
+			minY = n.Y
+		}
+		if i == 0 || n.X+n.Width > maxX {
+			maxX = n.X + n.Width
+		}
+		if i == 0 || n.Y+n.Height > maxY {
+			maxY = n.Y + n.Height
+		}
+	}
+	return minX, minY, maxX, maxY
+}
+
+// drawOrder returns the nodes with groups first (largest first) so cards
+// are drawn on top of the groups containing them
+func (c *Canvas) drawOrder() []CanvasNode {
+	nodes := append([]CanvasNode(nil), c.Nodes...)
+	sort.SliceStable(nodes, func(i, j int) bool {
+		gi, gj := nodes[i].Type == "group", nodes[j].Type == "group"
+		if gi != gj {
+			return gi
+		}
+		if gi {
+			return nodes[i].Width*nodes[i].Height > nodes[j].Width*nodes[j].Height
+		}
+		return false
+	})
+	return nodes
+}
+
+// nodeSummary is the one-line description of file and link nodes
+func nodeSummary(n CanvasNode) string {
+	switch n.Type {
+	case "file":
+		icon := "📄"
+		if isSupportedImageFormat(n.File) {
+			icon = "🖼️"
+		}
+		return icon + " " + n.File + n.Subpath
+	case "link":
+		return "🔗 " + n.URL
+	}
+	return ""
+}
+
+// edgeSides picks the sides an edge leaves and enters its nodes from when
+// the canvas does not say, based on where the nodes are relative to each other
+func edgeSides(edge CanvasEdge, from, to CanvasNode) (string, string) {
+	fromSide, toSide := edge.FromSide, edge.ToSide
+	dx := float64(to.X+to.Width/2) - float64(from.X+from.Width/2)
+	dy := float64(to.Y+to.Height/2) - float64(from.Y+from.Height/2)
+	horizontal := math.Abs(dx) > math.Abs(dy)
+
+	if fromSide == "" {
+		switch {
+		case horizontal && dx > 0:
+			fromSide = "right"
+		case horizontal:
+			fromSide = "left"
+		case dy > 0:
+			fromSide = "bottom"
+		default:
+			fromSide = "top"
+		}
+	}
+	if toSide == "" {
+		switch {
+		case horizontal && dx > 0:
+			toSide = "left"
+		case horizontal:
+			toSide = "right"
+		case dy > 0:
+			toSide = "top"
+		default:
+			toSide = "bottom"
+		}
+	}
+	return fromSide, toSide
+}
+
+// Terminal layout: a canvas pixel-to-cell scale of 8 px per column and
+// 16 px per row at most, shrunk to fit the terminal width (but drawn at least
+// canvasMinWidth columns wide)
+const (
+	canvasPixelsPerColumn = 8.0
+	canvasMinWidth        = 20
+	canvasMinBoxWidth     = 8
+	canvasMinBoxHeight    = 3
+)
+
+// canvasCell is one terminal cell of a drawn canvas
+type canvasCell struct {
+	ch    string // Grapheme drawn in the cell ("" continues a wide one)
+	style string // SGR sequences applied to the cell
+}
+
+// canvasGrid is a character grid the canvas is drawn onto
+type canvasGrid struct {
+	width, height int
+	cells         [][]canvasCell
+}
+
+// cellRect is a node's position on the grid
+type cellRect struct {
+	x, y, w, h int
+}
+
+// RenderCanvas draws a canvas as a box diagram for the terminal: text nodes
+// are rendered as markdown, file and link nodes as links, and edges as
+// arrows routed between the boxes
+func (r *Renderer) RenderCanvas(canvas *Canvas) (string, error) {
+	if len(canvas.Nodes) == 0 {
+		return "(empty canvas)\n", nil
+	}
+
+	// Scale the canvas to the terminal width; cells are about twice as tall
+	// as they are wide
+	minX, minY, maxX, _ := canvas.bounds()
+	width := max(r.options.Width-2, canvasMinWidth)
+	scale := canvasPixelsPerColumn
+	if float64(maxX-minX)/scale > float64(width-1) {
+		scale = float64(maxX-minX) / float64(width-1)
+	}
+
+	rects := make(map[string]cellRect)
+	height := 0
+	for _, n := range canvas.Nodes {
+		rect := cellRect{
+			x: int(math.Round(float64(n.X-minX) / scale)),
+			y: int(math.Round(float64(n.Y-minY) / (2 * scale))),
+			w: max(int(math.Round(float64(n.Width)/scale)), canvasMinBoxWidth),
+			h: max(int(math.Round(float64(n.Height)/(2*scale))), canvasMinBoxHeight),
+		}
+		if rect.x+rect.w > width {
+			rect.x = max(width-rect.w, 0)
+			rect.w = min(rect.w, width)
+		}
+		rects[n.ID] = rect
+		height = max(height, rect.y+rect.h)
+	}
+
+	grid := newCanvasGrid(width, height+1)
+
+	nodes := canvas.drawOrder()
+	for _, n := range nodes {
+		if n.Type == "group" {
+			grid.box(rects[n.ID], canvasStyle(n.Color), n.Label)
+		}
+	}
+	for _, edge := range canvas.Edges {
+		from, ok1 := canvas.node(edge.FromNode)
+		to, ok2 := canvas.node(edge.ToNode)
+		if !ok1 || !ok2 {
+			continue
+		}
+		grid.edge(edge, from, to, rects[from.ID], rects[to.ID])
+	}
+	for _, n := range nodes {
+		if n.Type == "group" {
+			continue
+		}
+		rect := rects[n.ID]
+		grid.box(rect, canvasStyle(n.Color), "")
+		if err := r.drawCanvasNode(grid, n, rect); err != nil {
+			return "", err
+		}
+	}
+
+	// Hyperlinks are applied row by row, so a link whose end marker was
+	// drawn over cannot run into the rest of the canvas
+	rows := strings.SplitAfter(grid.String(), "\n")
+	for i, row := range rows {
+		rows[i] = applyHyperlinks(row, r.links)
+	}
+	return strings.Join(rows, ""), nil
+}
+
+// drawCanvasNode fills a node's box with its content
+func (r *Renderer) drawCanvasNode(grid *canvasGrid, n CanvasNode, rect cellRect) error {
+	innerW, innerH := rect.w-4, rect.h-2
+	if innerW < 1 || innerH < 1 {
+		return nil
+	}
+
+	var lines []string
+	if n.Type == "text" {
+		glamourRenderer, err := newGlamourRenderer(r.options.Style, innerW)
+		if err != nil {
+			return fmt.Errorf("failed to create canvas renderer: %w", err)
+		}
+		// Canvas cells cannot hold hyperlinks
+		resolver := r.linkResolver()
+		resolver.links = nil
+		rendered, err := glamourRenderer.Render(preprocessLinks(n.Text, resolver))
+		if err != nil {
+			return fmt.Errorf("failed to render canvas node %s: %w", n.ID, err)
+		}
+		lines = strings.Split(trimRenderedBlock(applyInlineStyles(rendered)), "\n")
+	} else {
+		summary := ansi.Truncate(nodeSummary(n), innerW, "…")
+		// The link marker follows the icon: zero-width runes at the start
+		// of a line have no cell to attach to
+		if target, ok := r.canvasNodeTarget(n); ok && r.links != nil {
+			if icon, name, found := strings.Cut(summary, " "); found {
+				summary = icon + " " + r.links.marker(target) + name + string(linkEnd)
+			}
+		}
+		lines = []string{"\x1b[4m" + summary + "\x1b[0m"}
+	}
+
+	if len(lines) > innerH {
+		lines = append(lines[:innerH-1], "…")
+	}
+	for i, line := range lines {
+		grid.styled(rect.x+2, rect.y+1+i, innerW, line)
+	}
+	return nil
+}
+
+// canvasNodeTarget returns the hyperlink target of a file or link node. File
+// nodes are resolved through the vault, like wiki-links.
+func (r *Renderer) canvasNodeTarget(n CanvasNode) (string, bool) {
+	resolver := r.linkResolver()
+	switch n.Type {
+	case "file":
+		href := n.File
+		if resolved, ok := resolver.resolveFile(n.File); ok {
+			href = resolved
+		}
+		return resolver.hyperlinkTarget(href + n.Subpath)
+	case "link":
+		return resolver.hyperlinkTarget(n.URL)
+	}
+	return "", false
+}
+
+// canvasStyle returns the SGR sequence for a node or edge color
+func canvasStyle(color string) string {
+	if c, ok := canvasColors[color]; ok {
+		return "\x1b[38;5;" + c.ANSI + "m"
+	}
+	if strings.HasPrefix(color, "#") && len(color) == 7 {
+		var red, green, blue int
+		if _, err := fmt.Sscanf(color, "#%02x%02x%02x", &red, &green, &blue); err == nil {
+			return fmt.Sprintf("\x1b[38;2;%d;%d;%dm", red, green, blue)
+		}
+	}
+	return "\x1b[38;5;" + canvasColors[""].ANSI + "m"
+}
+
+// newCanvasGrid creates an empty grid
+func newCanvasGrid(width, height int) *canvasGrid {
+	cells := make([][]canvasCell, height)
+	for y := range cells {
+		cells[y] = make([]canvasCell, width)
+		for x := range cells[y] {
+			cells[y][x] = canvasCell{ch: " "}
+		}
+	}
+	return &canvasGrid{width: width, height: height, cells: cells}
+}
+
+// set draws a single-width character
+func (g *canvasGrid) set(x, y int, ch, style string) {
+	if x < 0 || y < 0 || x >= g.width || y >= g.height {
+		return
+	}
+	g.cells[y][x] = canvasCell{ch: ch, style: style}
+}
+
+// get returns the character in a cell
+func (g *canvasGrid) get(x, y int) string {
+	if x < 0 || y < 0 || x >= g.width || y >= g.height {
+		return ""
+	}
+	return g.cells[y][x].ch
+}
+
+// box draws a rounded box, clearing its interior. A label is set into the
+// top border.
+func (g *canvasGrid) box(rect cellRect, style, label string) {
+	for y := rect.y; y < rect.y+rect.h; y++ {
+		for x := rect.x; x < rect.x+rect.w; x++ {
+			ch := " "
+			switch {
+			case y == rect.y && x == rect.x:
+				ch = "╭"
+			case y == rect.y && x == rect.x+rect.w-1:
+				ch = "╮"
+			case y == rect.y+rect.h-1 && x == rect.x:
+				ch = "╰"
+			case y == rect.y+rect.h-1 && x == rect.x+rect.w-1:
+				ch = "╯"
+			case y == rect.y || y == rect.y+rect.h-1:
+				ch = "─"
+			case x == rect.x || x == rect.x+rect.w-1:
+				ch = "│"
+			}
+			if ch == " " {
+				g.set(x, y, ch, "")
+			} else {
+				g.set(x, y, ch, style)
+			}
+		}
+	}
+	if label != "" {
+		label = ansi.Truncate(" "+label+" ", rect.w-4, "…")
+		g.styled(rect.x+2, rect.y, rect.w-4, "\x1b[1m"+style+label)
+	}
+}
+
+// styled draws a line that may contain ANSI styles, at most maxW cells wide
+func (g *canvasGrid) styled(x, y, maxW int, line string) {
+	style := ""
+	col := x
+	for i := 0; i < len(line); {
+		if line[i] == '\x1b' && i+1 < len(line) && line[i+1] == '[' {
+			j := i + 2
+			for j < len(line) && (line[j] < 0x40 || line[j] > 0x7e) {
+				j++
+			}
+			if j < len(line) {
+				j++
+			}
+			seq := line[i:j]
+			if seq == "\x1b[0m" || seq == "\x1b[m" {
+				style = ""
+			} else if strings.HasSuffix(seq, "m") {
+				style += seq
+			}
+			i = j
+			continue
+		}
+
+		// Take one grapheme: a rune plus any zero-width runes after it
+		cluster, w := firstCluster(line[i:])
+		i += len(cluster)
+		if w == 0 {
+			if col > x && y >= 0 && y < g.height && col-1 < g.width {
+				g.cells[y][col-1].ch += cluster
+			}
+			continue
+		}
+		if col+w > x+maxW {
+			break
+		}
+		g.set(col, y, cluster, style)
+		for k := 1; k < w; k++ {
+			g.set(col+k, y, "", style)
+		}
+		col += w
+	}
+}
+
+// firstCluster returns the first grapheme cluster of s and its width
+func firstCluster(s string) (string, int) {
+	for _, r := range s {
+		cluster := string(r)
+		rest := s[len(cluster):]
+		for _, next := range rest {
+			if ansi.StringWidth(string(next)) != 0 || next == '\x1b' {
+				break
+			}
+			cluster += string(next)
+		}
+		return cluster, ansi.StringWidth(cluster)
+	}
+	return "", 0
+}
+
+// edge routes an edge between two boxes as an elbow line with arrowheads
+func (g *canvasGrid) edge(edge CanvasEdge, from, to CanvasNode, fromRect, toRect cellRect) {
+	fromSide, toSide := edgeSides(edge, from, to)
+	x1, y1 := sidePoint(fromRect, fromSide)
+	x2, y2 := sidePoint(toRect, toSide)
+	style := canvasStyle(edge.Color)
+
+	// Leave horizontally from left/right sides, vertically otherwise
+	var points [][2]int
+	if fromSide == "left" || fromSide == "right" {
+		points = [][2]int{{x1, y1}, {x2, y1}, {x2, y2}}
+	} else {
+		points = [][2]int{{x1, y1}, {x1, y2}, {x2, y2}}
+	}
+	for i := 0; i+1 < len(points); i++ {
+		g.line(points[i], points[i+1], style)
+	}
+	// Round the elbow
+	if mid := points[1]; mid != points[0] && mid != points[2] {
+		g.set(mid[0], mid[1], elbow(points[0], mid, points[2]), style)
+	}
+
+	if edge.ToEnd != "none" {
+		g.set(x2, y2, arrowHead(toSide), style)
+	}
+	if edge.FromEnd == "arrow" {
+		g.set(x1, y1, arrowHead(fromSide), style)
+	}
+
+	if edge.Label != "" {
+		// Put the label on the longest segment
+		a, b := points[0], points[1]
+		if abs(points[2][0]-points[1][0])+abs(points[2][1]-points[1][1]) > abs(b[0]-a[0])+abs(b[1]-a[1]) {
+			a, b = points[1], points[2]
+		}
+		label := " " + edge.Label + " "
+		lx := (a[0]+b[0])/2 - ansi.StringWidth(label)/2
+		g.styled(max(lx, 0), (a[1]+b[1])/2, g.width, style+label)
+	}
+}
+
+// sidePoint returns the cell just outside the middle of a box side
+func sidePoint(rect cellRect, side string) (int, int) {
+	switch side {
+	case "top":
+		return rect.x + rect.w/2, rect.y - 1
+	case "bottom":
+		return rect.x + rect.w/2, rect.y + rect.h
+	case "left":
+		return rect.x - 1, rect.y + rect.h/2
+	default:
+		return rect.x + rect.w, rect.y + rect.h/2
+	}
+}
+
+// line draws a horizontal or vertical segment, joining crossing lines
+func (g *canvasGrid) line(a, b [2]int, style string) {
+	dx, dy := sign(b[0]-a[0]), sign(b[1]-a[1])
+	x, y := a[0], a[1]
+	for {
+		ch := "─"
+		if dx == 0 {
+			ch = "│"
+		}
+		if existing := g.get(x, y); existing == "┼" || (existing == "─" && ch == "│") || (existing == "│" && ch == "─") {
+			ch = "┼"
+		}
+		g.set(x, y, ch, style)
+		if x == b[0] && y == b[1] {
+			return
+		}
+		x, y = x+dx, y+dy
+	}
+}
+
+// elbow returns the rounded corner joining segments a-mid and mid-b
+func elbow(a, mid, b [2]int) string {
+	// Directions from the corner towards both ends
+	left := a[0] < mid[0] || b[0] < mid[0]
+	up := a[1] < mid[1] || b[1] < mid[1]
+	switch {
+	case left && up:
+		return "╯"
+	case left:
+		return "╮"
+	case up:
+		return "╰"
+	default:
+		return "╭"
+	}
+}
+
+// arrowHead returns the arrow pointing into a box from the given side
+func arrowHead(side string) string {
+	switch side {
+	case "top":
+		return "▼"
+	case "bottom":
+		return "▲"
+	case "left":
+		return "▶"
+	default:
+		return "◀"
+	}
+}
+
+// String renders the grid, trimming trailing blank cells
+func (g *canvasGrid) String() string {
+	var out strings.Builder
+	for _, row := range g.cells {
+		end := len(row)
+		for end > 0 && row[end-1].ch == " " {
+			end--
+		}
+
+		out.WriteString("  ")
+		current := ""
+		for _, cell := range row[:end] {
+			if cell.style != current {
+				out.WriteString("\x1b[0m" + cell.style)
+				current = cell.style
+			}
+			out.WriteString(cell.ch)
+		}
+		if current != "" {
+			out.WriteString("\x1b[0m")
+		}
+		out.WriteString("\n")
+	}
+	return out.String()
+}
+
+// sign returns -1, 0 or 1
+func sign(n int) int {
+	switch {
+	case n > 0:
+		return 1
+	case n < 0:
+		return -1
+	}
+	return 0
+}
+
+// abs returns the absolute value of n
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
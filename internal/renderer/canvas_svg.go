@@ -0,0 +1,197 @@
+package renderer
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"math"
+	"strings"
+)
+
+// canvasSVGPadding is the margin around the nodes, in canvas pixels
+const canvasSVGPadding = 40
+
+// RenderCanvasToHTML renders a canvas as a positioned SVG inside a complete
+// HTML document, for PDF export
+func (r *HTMLRenderer) RenderCanvasToHTML(canvas *Canvas) (string, error) {
+	svg, err := r.canvasSVG(canvas)
+	if err != nil {
+		return "", err
+	}
+	r.frontmatter = nil
+	return r.wrapHTML(svg, "")
+}
+
+// canvasSVG draws the canvas at its own coordinates: groups, then edges,
+// then cards. Text cards are rendered as HTML inside <foreignObject>.
+func (r *HTMLRenderer) canvasSVG(canvas *Canvas) (string, error) {
+	if len(canvas.Nodes) == 0 {
+		return "<p><em>(empty canvas)</em></p>", nil
+	}
+
+	minX, minY, maxX, maxY := canvas.bounds()
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg class="canvas" xmlns="http://www.w3.org/2000/svg" viewBox="%d %d %d %d" width="100%%">`,
+		minX-canvasSVGPadding, minY-canvasSVGPadding, maxX-minX+2*canvasSVGPadding, maxY-minY+2*canvasSVGPadding)
+	b.WriteString("\n")
+
+	// One arrowhead marker per edge color
+	b.WriteString("<defs>\n")
+	markers := make(map[string]string)
+	for _, edge := range canvas.Edges {
+		color := canvasCSSColor(edge.Color)
+		if _, ok := markers[color]; ok {
+			continue
+		}
+		id := fmt.Sprintf("canvas-arrow-%d", len(markers))
+		markers[color] = id
+		fmt.Fprintf(&b, `<marker id="%s" viewBox="0 0 10 10" refX="9" refY="5" markerWidth="8" markerHeight="8" orient="auto-start-reverse"><path d="M0,0 L10,5 L0,10 z" fill="%s"/></marker>`+"\n", id, color)
+	}
+	b.WriteString("</defs>\n")
+
+	nodes := canvas.drawOrder()
+	for _, n := range nodes {
+		if n.Type != "group" {
+			continue
+		}
+		color := canvasCSSColor(n.Color)
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" rx="12" fill="%s" fill-opacity="0.06" stroke="%s" stroke-width="2"/>`+"\n",
+			n.X, n.Y, n.Width, n.Height, color, color)
+		if n.Label != "" {
+			fmt.Fprintf(&b, `<text x="%d" y="%d" class="canvas-group-label" fill="%s">%s</text>`+"\n",
+				n.X+4, n.Y-10, color, html.EscapeString(n.Label))
+		}
+	}
+
+	for _, edge := range canvas.Edges {
+		from, ok1 := canvas.node(edge.FromNode)
+		to, ok2 := canvas.node(edge.ToNode)
+		if !ok1 || !ok2 {
+			continue
+		}
+		b.WriteString(canvasSVGEdge(edge, from, to, markers[canvasCSSColor(edge.Color)]))
+	}
+
+	for _, n := range nodes {
+		if n.Type == "group" {
+			continue
+		}
+		content, err := r.canvasNodeHTML(n)
+		if err != nil {
+			return "", err
+		}
+		color := canvasCSSColor(n.Color)
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" rx="8" fill="#fff" stroke="%s" stroke-width="2"/>`+"\n",
+			n.X, n.Y, n.Width, n.Height, color)
+		// Colored cards get a light tint of their color
+		if n.Color != "" {
+			fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" rx="8" fill="%s" fill-opacity="0.08"/>`+"\n",
+				n.X, n.Y, n.Width, n.Height, color)
+		}
+		fmt.Fprintf(&b, `<foreignObject x="%d" y="%d" width="%d" height="%d"><div xmlns="http://www.w3.org/1999/xhtml" class="canvas-node">%s</div></foreignObject>`+"\n",
+			n.X, n.Y, n.Width, n.Height, content)
+	}
+
+	b.WriteString("</svg>\n")
+	return b.String(), nil
+}
+
+// canvasNodeHTML renders the content of a card: markdown for text cards and
+// a link for file and link cards
+func (r *HTMLRenderer) canvasNodeHTML(n CanvasNode) (string, error) {
+	switch n.Type {
+	case "text":
+		var buf bytes.Buffer
+		if err := r.md.Convert([]byte(preprocessLinks(n.Text, r.linkResolver())), &buf); err != nil {
+			return "", fmt.Errorf("failed to render canvas node %s: %w", n.ID, err)
+		}
+		return buf.String(), nil
+	case "file":
+		href := n.File
+		if resolved, ok := r.linkResolver().resolveFile(n.File); ok {
+			href = resolved
+		}
+		return fmt.Sprintf(`<p><a href="%s">%s</a></p>`, html.EscapeString(href+n.Subpath), html.EscapeString(nodeSummary(n))), nil
+	case "link":
+		return fmt.Sprintf(`<p><a href="%s">%s</a></p>`, html.EscapeString(n.URL), html.EscapeString(nodeSummary(n))), nil
+	}
+	return "", nil
+}
+
+// canvasSVGEdge draws an edge as a curve leaving and entering the nodes
+// perpendicular to their sides, like Obsidian does
+func canvasSVGEdge(edge CanvasEdge, from, to CanvasNode, marker string) string {
+	fromSide, toSide := edgeSides(edge, from, to)
+	x1, y1 := svgSidePoint(from, fromSide)
+	x2, y2 := svgSidePoint(to, toSide)
+
+	// Control points stick out from the sides by half the distance
+	dist := math.Max(math.Hypot(x2-x1, y2-y1)/2, 40)
+	nx1, ny1 := sideNormal(fromSide)
+	nx2, ny2 := sideNormal(toSide)
+	cx1, cy1 := x1+nx1*dist, y1+ny1*dist
+	cx2, cy2 := x2+nx2*dist, y2+ny2*dist
+
+	color := canvasCSSColor(edge.Color)
+	attrs := ""
+	if edge.ToEnd != "none" {
+		attrs += fmt.Sprintf(` marker-end="url(#%s)"`, marker)
+	}
+	if edge.FromEnd == "arrow" {
+		attrs += fmt.Sprintf(` marker-start="url(#%s)"`, marker)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<path d="M %.1f %.1f C %.1f %.1f, %.1f %.1f, %.1f %.1f" fill="none" stroke="%s" stroke-width="2"%s/>`+"\n",
+		x1, y1, cx1, cy1, cx2, cy2, x2, y2, color, attrs)
+
+	if edge.Label != "" {
+		// Midpoint of the cubic Bézier curve
+		mx := (x1 + 3*cx1 + 3*cx2 + x2) / 8
+		my := (y1 + 3*cy1 + 3*cy2 + y2) / 8
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" class="canvas-edge-label" text-anchor="middle" dominant-baseline="middle">%s</text>`+"\n",
+			mx, my, html.EscapeString(edge.Label))
+	}
+	return b.String()
+}
+
+// svgSidePoint returns the middle of a node side
+func svgSidePoint(n CanvasNode, side string) (float64, float64) {
+	x, y := float64(n.X), float64(n.Y)
+	w, h := float64(n.Width), float64(n.Height)
+	switch side {
+	case "top":
+		return x + w/2, y
+	case "bottom":
+		return x + w/2, y + h
+	case "left":
+		return x, y + h/2
+	default:
+		return x + w, y + h/2
+	}
+}
+
+// sideNormal returns the outward direction of a node side
+func sideNormal(side string) (float64, float64) {
+	switch side {
+	case "top":
+		return 0, -1
+	case "bottom":
+		return 0, 1
+	case "left":
+		return -1, 0
+	default:
+		return 1, 0
+	}
+}
+
+// canvasCSSColor returns the CSS color for a node or edge color
+func canvasCSSColor(color string) string {
+	if c, ok := canvasColors[color]; ok {
+		return c.CSS
+	}
+	if strings.HasPrefix(color, "#") {
+		return html.EscapeString(color)
+	}
+	return canvasColors[""].CSS
+}
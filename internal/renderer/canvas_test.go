@@ -0,0 +1,83 @@
+package renderer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testCanvas = `{"nodes": [
+	{"id": "a", "type": "file", "file": "notes/My Note.md", "subpath": "#Intro", "x": 0, "y": 0, "width": 300, "height": 60},
+	{"id": "b", "type": "link", "url": "https://example.com", "x": 400, "y": 0, "width": 250, "height": 60}
+], "edges": [{"id": "e", "fromNode": "a", "toNode": "b"}]}`
+
+func TestRenderCanvasWidth(t *testing.T) {
+	canvas, err := ParseCanvas([]byte(testCanvas))
+	if err != nil {
+		t.Fatalf("ParseCanvas: %v", err)
+	}
+	for _, width := range []int{1, 2, 10, 80} {
+		r, err := NewRenderer(RenderOptions{Style: "dark", Width: width})
+		if err != nil {
+			t.Fatalf("NewRenderer: %v", err)
+		}
+		if _, err := r.RenderCanvas(canvas); err != nil {
+			t.Errorf("RenderCanvas at width %d: %v", width, err)
+		}
+	}
+}
+
+func TestRenderCanvasLinks(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{".obsidian", "notes"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, "notes", "My Note.md"), []byte("# Intro"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	canvas, err := ParseCanvas([]byte(testCanvas))
+	if err != nil {
+		t.Fatalf("ParseCanvas: %v", err)
+	}
+
+	tests := []struct {
+		hyperlinks bool
+		want       []string
+	}{
+		{true, []string{
+			Hyperlink(FileURL(filepath.Join(root, "notes", "My Note.md"), "Intro"), "notes/My Note.md#Intro"),
+			Hyperlink("https://example.com", "https://example.com"),
+		}},
+		{false, []string{"notes/My Note.md#Intro", "https://example.com"}},
+	}
+	for _, tt := range tests {
+		r, err := NewRenderer(RenderOptions{Style: "dark", Width: 120, Hyperlinks: tt.hyperlinks})
+		if err != nil {
+			t.Fatalf("NewRenderer: %v", err)
+		}
+		if err := r.SetSourcePath(filepath.Join(root, "board.canvas")); err != nil {
+			t.Fatalf("SetSourcePath: %v", err)
+		}
+		out, err := r.RenderCanvas(canvas)
+		if err != nil {
+			t.Fatalf("RenderCanvas: %v", err)
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(out, want) {
+				t.Errorf("hyperlinks %v: output does not contain %q:\n%q", tt.hyperlinks, want, out)
+			}
+		}
+		if !tt.hyperlinks && strings.Contains(out, "\x1b]8;") {
+			t.Errorf("hyperlinks disabled: output contains an OSC 8 link:\n%q", out)
+		}
+		// Every row closes the links it opens
+		for _, row := range strings.Split(out, "\n") {
+			if strings.Count(row, "\x1b]8;;\x1b\\") != strings.Count(row, "\x1b]8;;")-strings.Count(row, "\x1b]8;;\x1b\\") {
+				t.Errorf("unbalanced hyperlinks in row %q", row)
+			}
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package renderer
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// Footnote definition: "[^id]: text", continued by indented lines
+	footnoteDefRegexp = regexp.MustCompile(`^\[\^([^\]\s]+)\]:\s?(.*)$`)
+	// Footnote reference: [^id]
+	footnoteRefRegexp = regexp.MustCompile(`\[\^([^\]\s]+)\]`)
+)
+
+// terminalFootnotes replaces footnote references with superscript numbers
+// and moves the definitions to a numbered "Footnotes" section at the end, as
+// Glamour does not render footnotes. Numbers follow the order of the first
+// reference; definitions that are never referenced come last.
+func terminalFootnotes(lines []string) []string {
+	defs := make(map[string]string)
+	var order []string // Definition IDs in document order
+	var body []string
+	inCodeFence := false
+	current := ""
+	for _, line := range lines {
+		trim := strings.TrimSpace(line)
+		if strings.HasPrefix(trim, "```") || strings.HasPrefix(trim, "~~~") {
+			inCodeFence = !inCodeFence
+		}
+		if inCodeFence {
+			current = ""
+			body = append(body, line)
+			continue
+		}
+
+		if m := footnoteDefRegexp.FindStringSubmatch(line); m != nil {
+			current = m[1]
+			if _, ok := defs[current]; !ok {
+				order = append(order, current)
+			}
+			defs[current] = strings.TrimSpace(m[2])
+			continue
+		}
+		// Indented lines continue the definition above them
+		if current != "" && trim != "" && (strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t")) {
+			defs[current] = strings.TrimSpace(defs[current] + " " + trim)
+			continue
+		}
+		current = ""
+		body = append(body, line)
+	}
+	if len(defs) == 0 {
+		return lines
+	}
+
+	// Number the footnotes as they are referenced
+	numbers := make(map[string]int)
+	var numbered []string
+	inCodeFence = false
+	for i, line := range body {
+		trim := strings.TrimSpace(line)
+		if strings.HasPrefix(trim, "```") || strings.HasPrefix(trim, "~~~") {
+			inCodeFence = !inCodeFence
+		}
+		if inCodeFence || !strings.Contains(line, "[^") {
+			continue
+		}
+		body[i] = mapOutsideCode(line, func(text string) string {
+			return footnoteRefRegexp.ReplaceAllStringFunc(text, func(match string) string {
+				id := footnoteRefRegexp.FindStringSubmatch(match)[1]
+				if _, ok := defs[id]; !ok {
+					return match
+				}
+				if _, ok := numbers[id]; !ok {
+					numbered = append(numbered, id)
+					numbers[id] = len(numbered)
+				}
+				return superscriptNumber(numbers[id])
+			})
+		})
+	}
+	for _, id := range order {
+		if _, ok := numbers[id]; !ok {
+			numbered = append(numbered, id)
+			numbers[id] = len(numbered)
+		}
+	}
+
+	body = append(body, "", "---", "", "**Footnotes**", "")
+	for _, id := range numbered {
+		body = append(body, fmt.Sprintf("%d. %s", numbers[id], defs[id]))
+	}
+	return append(body, "")
+}
+
+// superscriptNumber writes n with superscript digits, e.g. ¹²
+func superscriptNumber(n int) string {
+	var b strings.Builder
+	for _, r := range strconv.Itoa(n) {
+		b.WriteRune(superscripts[r])
+	}
+	return b.String()
+}
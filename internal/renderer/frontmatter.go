@@ -0,0 +1,336 @@
+package renderer
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aquele_dinho/mdviewer/internal/utils"
+	"github.com/charmbracelet/lipgloss"
+	"gopkg.in/yaml.v3"
+)
+
+// Frontmatter is the YAML metadata block at the top of a document:
+//
+//	---
+//	title: Release notes
+//	tags: [docs, release]
+//	---
+type Frontmatter struct {
+	Data map[string]any // Parsed YAML
+	Keys []string       // Top-level keys in document order
+}
+
+// ParseFrontmatter splits YAML frontmatter from the start of a document and
+// returns it with the remaining body. Documents without frontmatter return
+// nil. Invalid YAML is still stripped from the body, and reported as an error.
+func ParseFrontmatter(content string) (*Frontmatter, string, error) {
+	text := strings.TrimPrefix(content, "\ufeff")
+	if !strings.HasPrefix(text, "---\n") && !strings.HasPrefix(text, "---\r\n") {
+		return nil, content, nil
+	}
+
+	lines := strings.SplitAfter(text, "\n")
+	end := -1
+	for i := 1; i < len(lines); i++ {
+		trim := strings.TrimRight(lines[i], "\r\n")
+		if trim == "---" || trim == "..." {
+			end = i
+			break
+		}
+	}
+	if end == -1 {
+		// An unclosed --- is a horizontal rule, not frontmatter
+		return nil, content, nil
+	}
+
+	raw := strings.Join(lines[1:end], "")
+	body := strings.Join(lines[end+1:], "")
+
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte(raw), &node); err != nil {
+		return nil, body, fmt.Errorf("invalid frontmatter: %w", err)
+	}
+	fm := &Frontmatter{Data: make(map[string]any)}
+	if len(node.Content) == 0 {
+		// Empty frontmatter
+		return fm, body, nil
+	}
+	if err := node.Decode(&fm.Data); err != nil {
+		return nil, body, fmt.Errorf("invalid frontmatter: %w", err)
+	}
+
+	mapping := node.Content[0]
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		fm.Keys = append(fm.Keys, mapping.Content[i].Value)
+	}
+
+	return fm, body, nil
+}
+
+// String returns a top-level value formatted for display, or "" if the key
+// is missing
+func (fm *Frontmatter) String(key string) string {
+	if fm == nil {
+		return ""
+	}
+	value, ok := fm.Data[key]
+	if !ok {
+		return ""
+	}
+	return formatFrontmatterValue(value)
+}
+
+// DocumentTitle combines title, author and date into a document title such
+// as "Release notes — Jane Doe, 2024-05-01". It returns "" if none are set.
+func (fm *Frontmatter) DocumentTitle() string {
+	title := fm.String("title")
+	var byline []string
+	for _, key := range []string{"author", "date"} {
+		if value := fm.String(key); value != "" {
+			byline = append(byline, value)
+		}
+	}
+
+	switch {
+	case title != "" && len(byline) > 0:
+		return title + " — " + strings.Join(byline, ", ")
+	case title != "":
+		return title
+	default:
+		return strings.Join(byline, ", ")
+	}
+}
+
+// displayKeys returns the keys shown in the metadata table. The "mdviewer"
+// key holds rendering options, not metadata, and is left out.
+func (fm *Frontmatter) displayKeys() []string {
+	var keys []string
+	for _, key := range fm.Keys {
+		if key != "mdviewer" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// formatFrontmatterValue formats a YAML value on one line: lists are joined
+// with commas and dates without a time of day are shown as YYYY-MM-DD
+func formatFrontmatterValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case time.Time:
+		if v.Hour() == 0 && v.Minute() == 0 && v.Second() == 0 {
+			return v.Format("2006-01-02")
+		}
+		return v.Format("2006-01-02 15:04")
+	case []any:
+		var parts []string
+		for _, item := range v {
+			parts = append(parts, formatFrontmatterValue(item))
+		}
+		return strings.Join(parts, ", ")
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var parts []string
+		for _, k := range keys {
+			parts = append(parts, k+": "+formatFrontmatterValue(v[k]))
+		}
+		return strings.Join(parts, ", ")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// StripFrontmatter removes the frontmatter from a document and keeps it for
+// Frontmatter and RenderFrontmatter. Invalid YAML produces a warning.
+func (r *Renderer) StripFrontmatter(content string) string {
+	fm, body, err := ParseFrontmatter(content)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	r.frontmatter = fm
+	return body
+}
+
+// Frontmatter returns the metadata of the last parsed document, or nil
+func (r *Renderer) Frontmatter() map[string]any {
+	if r.frontmatter == nil {
+		return nil
+	}
+	return r.frontmatter.Data
+}
+
+// RenderFrontmatter draws the document metadata as a compact key/value table.
+// It returns "" if there is none or NoFrontmatter is set.
+func (r *Renderer) RenderFrontmatter() string {
+	fm := r.frontmatter
+	if fm == nil || r.options.NoFrontmatter {
+		return ""
+	}
+	keys := fm.displayKeys()
+	if len(keys) == 0 {
+		return ""
+	}
+
+	keyWidth, valueWidth := 0, 0
+	for _, key := range keys {
+		keyWidth = max(keyWidth, lipgloss.Width(key))
+		valueWidth = max(valueWidth, lipgloss.Width(fm.String(key)))
+	}
+	// Long values wrap within the terminal width
+	valueWidth = max(min(valueWidth, r.options.Width-keyWidth-10), 20)
+
+	keyStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("244")).Width(keyWidth + 2)
+	valueStyle := lipgloss.NewStyle().Width(valueWidth)
+
+	var rows []string
+	for _, key := range keys {
+		rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top,
+			keyStyle.Render(key),
+			valueStyle.Render(fm.String(key)),
+		))
+	}
+
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("240")).
+		Padding(0, 1).
+		MarginLeft(2).
+		Render(strings.Join(rows, "\n"))
+
+	return "\n" + box + "\n"
+}
+
+// frontmatterToHTML renders the document metadata as a table
+func frontmatterToHTML(fm *Frontmatter) string {
+	if fm == nil {
+		return ""
+	}
+	keys := fm.displayKeys()
+	if len(keys) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(`<table class="frontmatter">` + "\n")
+	for _, key := range keys {
+		fmt.Fprintf(&b, "<tr><th>%s</th><td>%s</td></tr>\n", html.EscapeString(key), html.EscapeString(fm.String(key)))
+	}
+	b.WriteString("</table>\n")
+	return b.String()
+}
+
+// DocumentOptions are per-document rendering settings from the "mdviewer:"
+// frontmatter key:
+//
+//	mdviewer:
+//	  style: dark
+//	  width: 160
+//	  mermaid-theme: forest
+//	  pdf:
+//	    paper: a4
+//	    landscape: true
+type DocumentOptions struct {
+	Style        string             `yaml:"style"`
+	Width        int                `yaml:"width"`
+	MermaidMode  string             `yaml:"mermaid-mode"`
+	MermaidTheme string             `yaml:"mermaid-theme"`
+	PDF          DocumentPDFOptions `yaml:"pdf"`
+}
+
+// DocumentPDFOptions are the PDF page settings of a document
+type DocumentPDFOptions struct {
+	Paper     string  `yaml:"paper"`     // Paper size, e.g. "a4" or "6inx9in"
+	Landscape *bool   `yaml:"landscape"` // nil keeps the default
+	Margin    string  `yaml:"margin"`    // One to four lengths such as "15mm" or "1in 0.5in"
+	Scale     float64 `yaml:"scale"`     // Content scale, 0 keeps the default
+
+	Header      string `yaml:"header"`       // Header template, or a template file
+	Footer      string `yaml:"footer"`       // Footer template, or a template file
+	Label       string `yaml:"label"`        // Classification label on every page
+	Logo        string `yaml:"logo"`         // Logo image in the header
+	PageNumbers *bool  `yaml:"page-numbers"` // Page numbers in the footer
+	TOC         *bool  `yaml:"toc"`          // Contents page with page numbers
+
+	Theme string `yaml:"theme"` // Built-in theme: github, academic, dark, compact
+	CSS   string `yaml:"css"`   // Stylesheet file added after the theme
+
+	CodeStyle   string `yaml:"code-style"`   // Chroma style of code blocks
+	LineNumbers *bool  `yaml:"line-numbers"` // Number the lines of code blocks
+}
+
+// DocumentOptions returns the "mdviewer:" settings of the frontmatter, or
+// nil if there are none
+func (fm *Frontmatter) DocumentOptions() (*DocumentOptions, error) {
+	if fm == nil {
+		return nil, nil
+	}
+	value, ok := fm.Data["mdviewer"]
+	if !ok || value == nil {
+		return nil, nil
+	}
+
+	// Round-trip through YAML to decode into the struct, rejecting unknown keys
+	raw, err := yaml.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mdviewer options: %w", err)
+	}
+	var opts DocumentOptions
+	decoder := yaml.NewDecoder(strings.NewReader(string(raw)))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&opts); err != nil {
+		return nil, fmt.Errorf("invalid mdviewer options: %w", err)
+	}
+	return &opts, nil
+}
+
+// ReadDocumentOptions returns the "mdviewer:" frontmatter settings of a file,
+// or nil if it has none. Problems are reported as warnings.
+func ReadDocumentOptions(path string) *DocumentOptions {
+	content, err := utils.ReadFile(path)
+	if err != nil {
+		// The viewer reports unreadable files
+		return nil
+	}
+	fm, _, err := ParseFrontmatter(string(content))
+	if err != nil {
+		return nil
+	}
+	docOpts, err := fm.DocumentOptions()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return nil
+	}
+	return docOpts
+}
+
+// Apply copies the document settings into opts. Settings whose command line
+// flag was set explicitly (explicit reports true for the flag name) are kept.
+func (o *DocumentOptions) Apply(opts *RenderOptions, explicit func(flag string) bool) {
+	if o.Style != "" && !explicit("style") {
+		opts.Style = o.Style
+	}
+	if o.Width > 0 && !explicit("width") {
+		opts.Width = o.Width
+	}
+	if o.MermaidMode != "" && !explicit("mermaid-mode") {
+		opts.MermaidMode = o.MermaidMode
+	}
+	if o.MermaidTheme != "" && !explicit("mermaid-theme") {
+		opts.MermaidTheme = o.MermaidTheme
+	}
+	if o.PDF.TOC != nil && !explicit("pdf-toc") {
+		opts.PDFTOC = *o.PDF.TOC
+	}
+}
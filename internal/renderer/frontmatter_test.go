@@ -0,0 +1,121 @@
+package renderer
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParseFrontmatter(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		keys    []string // nil: no frontmatter
+		body    string
+		err     bool
+	}{
+		{"none", "# Title\n", nil, "# Title\n", false},
+		{"basic", "---\ntitle: Notes\ntags: [a, b]\n---\n# Body\n", []string{"title", "tags"}, "# Body\n", false},
+		{"CRLF", "---\r\ntitle: Notes\r\n---\r\nBody", []string{"title"}, "Body", false},
+		{"BOM", "\ufeff---\ntitle: Notes\n---\nBody", []string{"title"}, "Body", false},
+		{"dots terminator", "---\ntitle: Notes\n...\nBody", []string{"title"}, "Body", false},
+		{"empty", "---\n---\nBody", []string{}, "Body", false},
+		{"unclosed", "---\ntitle: Notes\n", nil, "---\ntitle: Notes\n", false},
+		{"not at the start", "Text\n---\ntitle: Notes\n---\n", nil, "Text\n---\ntitle: Notes\n---\n", false},
+		{"invalid YAML", "---\ntitle: [unclosed\n---\nBody", nil, "Body", true},
+	}
+	for _, tt := range tests {
+		fm, body, err := ParseFrontmatter(tt.content)
+		if (err != nil) != tt.err {
+			t.Errorf("%s: err = %v, want error %v", tt.name, err, tt.err)
+		}
+		if body != tt.body {
+			t.Errorf("%s: body = %q, want %q", tt.name, body, tt.body)
+		}
+		if (fm == nil) != (tt.keys == nil) {
+			t.Errorf("%s: frontmatter = %v, want keys %q", tt.name, fm, tt.keys)
+			continue
+		}
+		if fm != nil && strings.Join(fm.Keys, ",") != strings.Join(tt.keys, ",") {
+			t.Errorf("%s: keys = %q, want %q", tt.name, fm.Keys, tt.keys)
+		}
+	}
+}
+
+func TestFrontmatterDocumentTitle(t *testing.T) {
+	tests := []struct {
+		yaml string
+		want string
+	}{
+		{"title: Notes\nauthor: Jane Doe\ndate: 2024-05-01", "Notes — Jane Doe, 2024-05-01"},
+		{"title: Notes", "Notes"},
+		{"author: Jane Doe", "Jane Doe"},
+		{"tags: [a]", ""},
+	}
+	for _, tt := range tests {
+		fm, _, err := ParseFrontmatter("---\n" + tt.yaml + "\n---\n")
+		if err != nil {
+			t.Fatalf("ParseFrontmatter: %v", err)
+		}
+		if got := fm.DocumentTitle(); got != tt.want {
+			t.Errorf("DocumentTitle of %q = %q, want %q", tt.yaml, got, tt.want)
+		}
+	}
+}
+
+func TestFrontmatterDocumentOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want string // Formatted options, "" for none
+		err  string
+	}{
+		{"no options", "title: Notes", "", ""},
+		{"empty options", "mdviewer:", "", ""},
+		{"options", "mdviewer:\n  style: dark\n  width: 160\n  pdf:\n    paper: a4\n    landscape: true",
+			"dark 160 a4 true", ""},
+		{"unknown key", "mdviewer:\n  colour: dark", "", "field colour not found"},
+		{"unknown PDF key", "mdviewer:\n  pdf:\n    papper: a4", "", "field papper not found"},
+		{"wrong type", "mdviewer:\n  width: wide", "", "invalid mdviewer options"},
+	}
+	for _, tt := range tests {
+		fm, _, err := ParseFrontmatter("---\n" + tt.yaml + "\n---\n")
+		if err != nil {
+			t.Fatalf("%s: ParseFrontmatter: %v", tt.name, err)
+		}
+		opts, err := fm.DocumentOptions()
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s: err = %v, want %q", tt.name, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		got := ""
+		if opts != nil {
+			landscape := opts.PDF.Landscape != nil && *opts.PDF.Landscape
+			got = fmt.Sprintf("%s %d %s %v", opts.Style, opts.Width, opts.PDF.Paper, landscape)
+		}
+		if got != tt.want {
+			t.Errorf("%s: options = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDocumentOptionsApply(t *testing.T) {
+	toc := true
+	doc := &DocumentOptions{Style: "dark", Width: 160, MermaidTheme: "forest", PDF: DocumentPDFOptions{TOC: &toc}}
+	opts := RenderOptions{Style: "light", Width: 80, MermaidTheme: "default"}
+
+	doc.Apply(&opts, func(flag string) bool { return flag == "width" })
+
+	if opts.Style != "dark" || opts.MermaidTheme != "forest" || !opts.PDFTOC {
+		t.Errorf("document settings not applied: %+v", opts)
+	}
+	if opts.Width != 80 {
+		t.Errorf("Width = %d, want the explicit flag value 80", opts.Width)
+	}
+}
@@ -3,26 +3,63 @@ package renderer
 import (
 	"bytes"
 	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
 	"regexp"
+	"strings"
 
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/aquele_dinho/mdviewer/internal/katex"
 	"github.com/aquele_dinho/mdviewer/internal/mermaid"
 	"github.com/yuin/goldmark"
+	highlighting "github.com/yuin/goldmark-highlighting/v2"
+	"github.com/yuin/goldmark/ast"
 	"github.com/yuin/goldmark/extension"
 	"github.com/yuin/goldmark/parser"
 	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
 )
 
 // HTMLRenderer converts markdown to HTML for PDF generation
 type HTMLRenderer struct {
-	md goldmark.Markdown
+	md              goldmark.Markdown
+	sourcePath      string             // Absolute path of the file being rendered ("" for stdin)
+	sourceDir       string             // Directory of the file being rendered ("" for stdin)
+	vault           *Vault             // Obsidian vault containing the file, if any
+	showComments    bool               // Keep Obsidian %%comments%% in the output
+	hideFrontmatter bool               // Leave the frontmatter metadata table out
+	mermaidTheme    string             // Mermaid theme ("" for the default)
+	toc             bool               // Insert a table of contents
+	printTOC        bool               // Start with a contents page with page numbers
+	section         string             // Only render the section under this heading
+	frontmatter     *Frontmatter       // Metadata of the document being rendered
+	theme           string             // Built-in stylesheet added to the base one
+	customCSS       string             // Stylesheet from --css
+	replaceCSS      bool               // Use customCSS instead of the built-in styles
+	template        *template.Template // Custom document template, if any
+	codeStyle       string             // Chroma style of code blocks ("" for the theme's)
+	lineNumbers     bool               // Number the lines of code blocks
+	ids             *headingIDs        // Heading IDs shared by the chapters of a book
+	footnotePrefix  string             // Prefix of footnote IDs, per book chapter
 }
 
 // NewHTMLRenderer creates a new HTML renderer
 func NewHTMLRenderer() *HTMLRenderer {
-	md := goldmark.New(
+	r := &HTMLRenderer{}
+	r.md = goldmark.New(
 		goldmark.WithExtensions(
 			extension.GFM,        // GitHub Flavored Markdown
 			extension.Typographer, // Smart quotes, dashes
+			// Footnote IDs are prefixed per chapter in books
+			extension.NewFootnote(extension.WithFootnoteIDPrefixFunction(func(ast.Node) []byte {
+				return []byte(r.footnotePrefix)
+			})),
+			extension.DefinitionList,
+			// Syntax highlighting with inline styles, so printing needs no stylesheet
+			highlighting.NewHighlighting(
+				highlighting.WithFormatOptions(chromahtml.TabWidth(4)),
+			),
 		),
 		goldmark.WithParserOptions(
 			parser.WithAutoHeadingID(),
@@ -35,114 +72,217 @@ func NewHTMLRenderer() *HTMLRenderer {
 			html.WithUnsafe(),
 		),
 	)
+	return r
+}
+
+// SetSourcePath tells the renderer which file is being rendered so wiki-links
+// and note embeds resolve the same way as in the terminal viewer
+func (r *HTMLRenderer) SetSourcePath(path string) error {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	r.sourcePath = absPath
+	r.sourceDir = filepath.Dir(absPath)
+
+	vault, err := FindVault(r.sourceDir)
+	if err != nil {
+		return err
+	}
+	r.vault = vault
+	return nil
+}
+
+// SetShowComments controls whether Obsidian %%comments%% are rendered
+func (r *HTMLRenderer) SetShowComments(show bool) {
+	r.showComments = show
+}
+
+// SetHideFrontmatter controls whether the frontmatter metadata table is left out
+func (r *HTMLRenderer) SetHideFrontmatter(hide bool) {
+	r.hideFrontmatter = hide
+}
+
+// SetMermaidTheme sets the theme diagrams are rendered with
+func (r *HTMLRenderer) SetMermaidTheme(theme string) {
+	r.mermaidTheme = theme
+}
+
+// SetTOC controls whether a table of contents is inserted
+func (r *HTMLRenderer) SetTOC(toc bool) {
+	r.toc = toc
+}
+
+// SetPrintTOC controls whether the document starts with a printed contents
+// page listing the page number of each heading
+func (r *HTMLRenderer) SetPrintTOC(printTOC bool) {
+	r.printTOC = printTOC
+}
+
+// SetSection restricts rendering to the section under the heading matching
+// query (see FindSection); "" renders the whole document
+func (r *HTMLRenderer) SetSection(query string) {
+	r.section = query
+}
 
-	return &HTMLRenderer{
-		md: md,
+// linkResolver returns the resolver for links in the file being rendered
+func (r *HTMLRenderer) linkResolver() linkResolver {
+	return linkResolver{
+		vault:        r.vault,
+		sourceDir:    r.sourceDir,
+		baseDir:      r.sourceDir,
+		embeds:       []string{r.sourcePath},
+		html:         true,
+		showComments: r.showComments,
 	}
 }
 
 // RenderToHTML converts markdown content to HTML
 func (r *HTMLRenderer) RenderToHTML(markdown string) (string, error) {
-	// First, process mermaid diagrams and replace with rendered SVGs
-	processed := r.processMermaidDiagrams(markdown)
+	d, err := r.parseDocument(markdown)
+	if err != nil {
+		return "", err
+	}
+	content, err := r.renderDocument(d)
+	if err != nil {
+		return "", err
+	}
+	toc := tocHTML(tocHeadings(d.headings), false)
+	if r.printTOC {
+		content = tocHTML(tocHeadings(d.headings), true) + content
+	}
+
+	// Wrap in a complete HTML document with styling
+	return r.wrapHTML(content, toc)
+}
+
+// htmlDocument is a parsed markdown document, ready to be rendered
+type htmlDocument struct {
+	doc         ast.Node
+	source      []byte
+	headings    []Heading
+	frontmatter string // Metadata table shown above the document
+}
 
+// parseDocument preprocesses and parses a markdown document, and resolves
+// its anchor links and images
+func (r *HTMLRenderer) parseDocument(markdown string) (*htmlDocument, error) {
+	// Split off the frontmatter; it becomes a metadata table and the title
+	fm, body, err := ParseFrontmatter(markdown)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	r.frontmatter = fm
+
+	showFrontmatter := !r.hideFrontmatter
+	if r.section != "" {
+		if body, err = FindSection(markdown, r.section); err != nil {
+			return nil, err
+		}
+		showFrontmatter = false
+	}
+	markdown = body
+
+	// Mark where the table of contents goes; it is filled in after parsing,
+	// when the heading IDs are known
+	placeholder := ""
+	if r.toc {
+		placeholder = tocPlaceholder
+	}
+	markdown = insertTOC(markdown, placeholder)
+
+	// Rewrite Obsidian links and inline syntax, and inline transcluded notes
+	processed := preprocessLinks(markdown, r.linkResolver())
+
+	// Turn callouts into styled containers
+	processed = calloutsToHTML(processed)
+
+	// Then process mermaid diagrams and replace with rendered SVGs
+	processed = r.processMermaidDiagrams(processed)
+
+	d := &htmlDocument{source: []byte(processed)}
+	if showFrontmatter {
+		d.frontmatter = frontmatterToHTML(fm)
+	}
+
+	ids := r.ids
+	if ids == nil {
+		ids = newHeadingIDs()
+	}
+	ctx := parser.NewContext(parser.WithIDs(ids))
+	d.doc = r.md.Parser().Parse(text.NewReader(d.source), parser.WithContext(ctx))
+	d.headings = astHeadings(d.doc, d.source)
+	resolveAnchorLinks(d.doc, d.headings)
+	r.embedImages(d.doc, d.source)
+	r.prepareCodeBlocks(d.doc, d.source)
+	return d, nil
+}
+
+// renderDocument renders a parsed document to HTML, with its metadata table
+// and table of contents
+func (r *HTMLRenderer) renderDocument(d *htmlDocument) (string, error) {
 	var buf bytes.Buffer
-	
-	if err := r.md.Convert([]byte(processed), &buf); err != nil {
+	buf.WriteString(d.frontmatter)
+	if err := r.md.Renderer().Render(&buf, d.source, d.doc); err != nil {
 		return "", fmt.Errorf("failed to convert markdown to HTML: %w", err)
 	}
+	content := buf.String()
+	if r.toc {
+		content = strings.Replace(content, tocPlaceholder, tocHTML(tocHeadings(d.headings), false), 1)
+	}
+	return content, nil
+}
 
-	// Wrap in a complete HTML document with styling
-	html := r.wrapHTML(buf.String())
-	return html, nil
+// FrontmatterValue returns a top-level frontmatter value of the last rendered
+// document formatted as text, or "" if it is not set
+func (r *HTMLRenderer) FrontmatterValue(key string) string {
+	return r.frontmatter.String(key)
+}
+
+// documentTitle returns the <title> of the document, built from the
+// frontmatter title, author and date
+func (r *HTMLRenderer) documentTitle() string {
+	if r.frontmatter != nil {
+		if title := r.frontmatter.DocumentTitle(); title != "" {
+			return title
+		}
+	}
+	return "Markdown Document"
 }
 
-// wrapHTML wraps the HTML content in a complete document with CSS
-func (r *HTMLRenderer) wrapHTML(content string) string {
+// mathAssets returns the KaTeX stylesheet for the <head> and the scripts that
+// typeset the formulas, if the content has math and KaTeX is embedded
+func mathAssets(content string) (string, string) {
+	if !strings.Contains(content, `class="math `) || !katex.Available() {
+		return "", ""
+	}
+	head := "<style>" + katex.Stylesheet() + "</style>"
+	script := "<script>" + katex.Script() + "</script>\n<script>" + katex.RenderScript() + "</script>"
+	return head, script
+}
+
+// wrapHTML wraps the HTML content in a complete document with CSS, or in the
+// custom template. toc is the table of contents for the template.
+func (r *HTMLRenderer) wrapHTML(content, toc string) (string, error) {
+	mathHead, mathScript := mathAssets(content)
+	if r.template != nil {
+		return r.executeTemplate(content, toc, mathHead, mathScript)
+	}
 	return fmt.Sprintf(`<!DOCTYPE html>
 <html lang="en">
 <head>
     <meta charset="UTF-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>Markdown Document</title>
+    <title>%s</title>
     <style>
-        body {
-            font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Helvetica, Arial, sans-serif;
-            line-height: 1.6;
-            color: #333;
-            max-width: 800px;
-            margin: 0 auto;
-            padding: 20px;
-        }
-        h1, h2, h3, h4, h5, h6 {
-            margin-top: 24px;
-            margin-bottom: 16px;
-            font-weight: 600;
-            line-height: 1.25;
-        }
-        h1 { font-size: 2em; border-bottom: 1px solid #eaecef; padding-bottom: 0.3em; }
-        h2 { font-size: 1.5em; border-bottom: 1px solid #eaecef; padding-bottom: 0.3em; }
-        h3 { font-size: 1.25em; }
-        code {
-            background-color: #f6f8fa;
-            padding: 0.2em 0.4em;
-            margin: 0;
-            font-size: 85%%;
-            border-radius: 3px;
-            font-family: 'SF Mono', Monaco, Consolas, monospace;
-        }
-        pre {
-            background-color: #f6f8fa;
-            padding: 16px;
-            overflow: auto;
-            border-radius: 6px;
-        }
-        pre code {
-            background-color: transparent;
-            padding: 0;
-        }
-        blockquote {
-            padding: 0 1em;
-            color: #6a737d;
-            border-left: 0.25em solid #dfe2e5;
-            margin: 0;
-        }
-        table {
-            border-collapse: collapse;
-            width: 100%%;
-        }
-        table th, table td {
-            padding: 6px 13px;
-            border: 1px solid #dfe2e5;
-        }
-        table tr:nth-child(2n) {
-            background-color: #f6f8fa;
-        }
-        img {
-            max-width: 100%%;
-        }
-        hr {
-            border: 0;
-            border-top: 1px solid #eaecef;
-            margin: 24px 0;
-        }
-        a {
-            color: #0366d6;
-            text-decoration: none;
-        }
-			a:hover {
-				text-decoration: underline;
-			}
-			.mermaid-diagram {
-				margin: 20px 0;
-				text-align: center;
-			}
-		</style>
+%s		</style>
+		%s
 	</head>
 	<body>
 	%s
+	%s
 	</body>
-	</html>`, content)
+	</html>`, template.HTMLEscapeString(r.documentTitle()), r.stylesheet(), mathHead, content, mathScript), nil
 }
 
 // processMermaidDiagrams detects mermaid code blocks and replaces them with rendered SVGs.
@@ -163,6 +303,7 @@ func (r *HTMLRenderer) processMermaidDiagrams(markdown string) string {
 		return markdown
 	}
 	defer compiler.Close()
+	compiler.SetTheme(r.mermaidTheme)
 
 	// Process markdown by replacing mermaid blocks with rendered SVGs
 	result := markdown
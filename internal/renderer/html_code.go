@@ -0,0 +1,86 @@
+package renderer
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// lineRangesRegexp matches highlighted line ranges after the language of a
+// code fence, as in ```go {3-5} or ```go {1,4-6}
+var lineRangesRegexp = regexp.MustCompile(`^\{\s*\d+(?:\s*-\s*\d+)?(?:\s*,\s*\d+(?:\s*-\s*\d+)?)*\s*\}$`)
+
+// SetCodeStyle sets the chroma style of highlighted code blocks ("" picks
+// github, or github-dark with the dark theme)
+func (r *HTMLRenderer) SetCodeStyle(name string) error {
+	if name != "" && !slices.Contains(styles.Names(), name) {
+		return fmt.Errorf("unknown code style %q (see https://xyproto.github.io/splash/docs/)", name)
+	}
+	r.codeStyle = name
+	return nil
+}
+
+// SetLineNumbers controls whether code blocks are printed with line numbers
+func (r *HTMLRenderer) SetLineNumbers(lineNumbers bool) {
+	r.lineNumbers = lineNumbers
+}
+
+// prepareCodeBlocks sets the highlighting options of the fenced code blocks
+// as node attributes, which the highlighter reads: the chroma style, line
+// numbers and the highlighted lines of ```lang {3-5}. Attributes written out
+// in the info string, such as {linenos=table}, are kept.
+func (r *HTMLRenderer) prepareCodeBlocks(doc ast.Node, source []byte) {
+	style := r.codeStyle
+	if style == "" {
+		style = "github"
+		if r.theme == "dark" {
+			style = "github-dark"
+		}
+	}
+
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		block, ok := n.(*ast.FencedCodeBlock)
+		if !entering || !ok {
+			return ast.WalkContinue, nil
+		}
+
+		if block.Info != nil {
+			info := block.Info.Segment.Value(source)
+			if idx := bytes.IndexByte(info, '{'); idx > 0 {
+				attrs := bytes.TrimSpace(info[idx:])
+				if lineRangesRegexp.Match(attrs) {
+					block.SetAttributeString("hl_lines", lineRanges(string(attrs)))
+				} else if parsed, ok := parser.ParseAttributes(text.NewReader(attrs)); ok {
+					for _, attr := range parsed {
+						block.SetAttribute(attr.Name, attr.Value)
+					}
+				}
+			}
+		}
+
+		if _, ok := block.AttributeString("hl_style"); !ok {
+			block.SetAttributeString("hl_style", []byte(style))
+		}
+		if _, ok := block.AttributeString("linenos"); !ok && r.lineNumbers {
+			block.SetAttributeString("linenos", true)
+		}
+		return ast.WalkSkipChildren, nil
+	})
+}
+
+// lineRanges converts "{1,3-5}" to the hl_lines values of the highlighter
+func lineRanges(attrs string) []any {
+	var ranges []any
+	for _, part := range strings.Split(strings.Trim(attrs, "{}"), ",") {
+		part = strings.ReplaceAll(strings.TrimSpace(part), " ", "")
+		ranges = append(ranges, []byte(part))
+	}
+	return ranges
+}
@@ -0,0 +1,107 @@
+package renderer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image/png"
+	"mime"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aquele_dinho/mdviewer/internal/utils"
+	"github.com/yuin/goldmark/ast"
+)
+
+// embedImages prepares the images of a parsed document for printing. Local
+// images are inlined as data URIs, as the PDF is printed from a page without
+// a base URL, and the |size hints of their alt text become CSS sizes, like
+// the terminal viewer's:
+//
+//	![alt|400](a.png)      400px wide
+//	![alt|400x300](a.png)  fit inside 400x300px
+//	![alt|x300](a.png)     300px high
+//	![alt|50%](a.png)      half of the page width
+func (r *HTMLRenderer) embedImages(doc ast.Node, source []byte) {
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		image, ok := n.(*ast.Image)
+		if !entering || !ok {
+			return ast.WalkContinue, nil
+		}
+
+		alt := astText(image, source)
+		if idx := strings.LastIndex(alt, "|"); idx != -1 {
+			if size, ok := parseImageSize(strings.TrimPrefix(alt[idx+1:], "width=")); ok {
+				image.RemoveChildren(image)
+				image.AppendChild(image, ast.NewString([]byte(alt[:idx])))
+				image.SetAttributeString("style", []byte(size.css()))
+			}
+		}
+
+		if src, err := r.imageDataURI(string(image.Destination)); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		} else if src != "" {
+			image.Destination = []byte(src)
+		}
+		return ast.WalkSkipChildren, nil
+	})
+}
+
+// css returns the CSS sizing of an image size hint
+func (s imageSize) css() string {
+	switch {
+	case s.percent > 0:
+		return fmt.Sprintf("width: %d%%", s.percent)
+	case s.width > 0 && s.height > 0:
+		return fmt.Sprintf("max-width: min(%dpx, 100%%); max-height: %dpx", s.width, s.height)
+	case s.height > 0:
+		return fmt.Sprintf("height: %dpx", s.height)
+	default:
+		return fmt.Sprintf("width: %dpx", s.width)
+	}
+}
+
+// imageDataURI reads a local image, relative to the source file, into a data
+// URI. It returns "" for remote images and data URIs. Formats Chrome cannot
+// display (TIFF) are converted to PNG.
+func (r *HTMLRenderer) imageDataURI(src string) (string, error) {
+	if src == "" || strings.HasPrefix(src, "data:") || strings.Contains(src, "://") {
+		return "", nil
+	}
+
+	path := src
+	if unescaped, err := url.PathUnescape(path); err == nil {
+		path = unescaped
+	}
+	path = filepath.FromSlash(path)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(r.sourceDir, path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read image %s: %w", src, err)
+	}
+
+	ext := strings.ToLower(filepath.Ext(path))
+	mimeType := mime.TypeByExtension(ext)
+	if ext == ".tif" || ext == ".tiff" {
+		img, _, err := utils.DecodeImage(data)
+		if err != nil {
+			return "", fmt.Errorf("failed to decode image %s: %w", src, err)
+		}
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil {
+			return "", fmt.Errorf("failed to convert image %s: %w", src, err)
+		}
+		data, mimeType = buf.Bytes(), "image/png"
+	}
+	if ext == ".bmp" {
+		mimeType = "image/bmp"
+	}
+	if !strings.HasPrefix(mimeType, "image/") {
+		return "", fmt.Errorf("unsupported image format: %s", src)
+	}
+	return "data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(data), nil
+}
@@ -0,0 +1,411 @@
+package renderer
+
+import (
+	"fmt"
+	"html/template"
+	"sort"
+	"strings"
+)
+
+// baseStylesheet is the default look of HTML and PDF output, modelled on
+// GitHub's markdown styling
+const baseStylesheet = `
+body {
+	font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Helvetica, Arial, sans-serif;
+	line-height: 1.6;
+	color: #333;
+	max-width: 800px;
+	margin: 0 auto;
+	padding: 20px;
+}
+h1, h2, h3, h4, h5, h6 {
+	margin-top: 24px;
+	margin-bottom: 16px;
+	font-weight: 600;
+	line-height: 1.25;
+}
+h1 { font-size: 2em; border-bottom: 1px solid #eaecef; padding-bottom: 0.3em; }
+h2 { font-size: 1.5em; border-bottom: 1px solid #eaecef; padding-bottom: 0.3em; }
+h3 { font-size: 1.25em; }
+code {
+	background-color: #f6f8fa;
+	padding: 0.2em 0.4em;
+	margin: 0;
+	font-size: 85%;
+	border-radius: 3px;
+	font-family: 'SF Mono', Monaco, Consolas, monospace;
+}
+pre {
+	background-color: #f6f8fa;
+	padding: 16px;
+	overflow: auto;
+	border-radius: 6px;
+}
+pre code {
+	background-color: transparent;
+	padding: 0;
+}
+blockquote {
+	padding: 0 1em;
+	color: #6a737d;
+	border-left: 0.25em solid #dfe2e5;
+	margin: 0;
+}
+table {
+	border-collapse: collapse;
+	width: 100%;
+}
+table th, table td {
+	padding: 6px 13px;
+	border: 1px solid #dfe2e5;
+}
+table tr:nth-child(2n) {
+	background-color: #f6f8fa;
+}
+img {
+	max-width: 100%;
+}
+hr {
+	border: 0;
+	border-top: 1px solid #eaecef;
+	margin: 24px 0;
+}
+a {
+	color: #0366d6;
+	text-decoration: none;
+}
+a:hover {
+	text-decoration: underline;
+}
+.mermaid-diagram {
+	margin: 20px 0;
+	text-align: center;
+}
+.callout {
+	margin: 16px 0;
+	padding: 8px 16px;
+	border-left: 4px solid var(--callout-color);
+	border-radius: 4px;
+	background-color: color-mix(in srgb, var(--callout-color) 8%, white);
+	page-break-inside: avoid;
+}
+.callout-title {
+	font-weight: 600;
+	color: var(--callout-color);
+	margin: 4px 0;
+}
+details.callout > summary {
+	cursor: pointer;
+}
+mark {
+	background-color: #fff3a3;
+	padding: 0 2px;
+}
+table.frontmatter {
+	font-size: 0.9em;
+	color: #555;
+}
+table.frontmatter th {
+	text-align: left;
+	font-weight: 600;
+	background-color: #f6f8fa;
+}
+svg.canvas {
+	display: block;
+	margin: 16px 0;
+}
+.canvas-node {
+	box-sizing: border-box;
+	height: 100%;
+	padding: 8px 14px;
+	overflow: hidden;
+	font-size: 14px;
+}
+.canvas-node > :first-child {
+	margin-top: 0;
+}
+.canvas-group-label {
+	font-size: 16px;
+	font-weight: 600;
+}
+.canvas-edge-label {
+	font-size: 13px;
+	fill: #555;
+	stroke: #fff;
+	stroke-width: 4px;
+	paint-order: stroke;
+}
+.tag {
+	display: inline-block;
+	padding: 0 8px;
+	border-radius: 10px;
+	background-color: #ddf4ff;
+	color: #0969da;
+	font-size: 0.85em;
+}
+nav.toc {
+	margin: 16px 0 24px;
+	padding: 8px 16px;
+	background-color: #f6f8fa;
+	border-radius: 6px;
+}
+nav.toc .toc-title {
+	font-weight: 600;
+	margin: 4px 0;
+}
+nav.toc ul {
+	margin: 0;
+	padding-left: 20px;
+}
+nav.toc > ul {
+	padding-left: 0;
+	list-style: none;
+}
+nav.toc-print {
+	break-after: page;
+	margin: 0;
+	padding: 0;
+	background: none;
+}
+nav.toc-print .toc-title {
+	font-size: 2em;
+	margin: 0 0 24px;
+}
+nav.toc-print ul {
+	list-style: none;
+}
+nav.toc-print li {
+	margin: 4px 0;
+}
+nav.toc-print a {
+	display: flex;
+	color: inherit;
+	text-decoration: none;
+}
+nav.toc-print .toc-leader {
+	flex: 1;
+	margin: 0 6px 5px;
+	border-bottom: 1px dotted #999;
+}
+dt {
+	font-weight: 600;
+	margin-top: 12px;
+}
+dd {
+	margin-left: 24px;
+}
+li:has(> input[type="checkbox"]) {
+	list-style: none;
+	margin-left: -1.2em;
+}
+.footnotes {
+	font-size: 0.9em;
+	color: #555;
+}
+.math-display {
+	display: block;
+	margin: 16px 0;
+	text-align: center;
+	overflow-x: auto;
+}
+.math:not(:has(.katex)) {
+	font-family: 'Cambria Math', 'STIX Two Math', 'Latin Modern Math', serif;
+}
+.callout-content > :first-child {
+	margin-top: 4px;
+}
+.callout-content > :last-child {
+	margin-bottom: 4px;
+}
+section.chapter + section.chapter {
+	break-before: page;
+}
+`
+
+// printStylesheet controls page breaks and shows collapsed callouts in
+// print. It is kept with --css-replace, as the page break markers rely on it.
+const printStylesheet = `
+.page-break {
+	break-after: page;
+}
+table, pre, .mermaid-diagram, .callout, .math-display, img, svg.canvas {
+	break-inside: avoid;
+}
+tr {
+	break-inside: avoid;
+}
+h1, h2, h3, h4, h5, h6 {
+	break-after: avoid;
+}
+@media print {
+	details.callout::details-content {
+		content-visibility: visible;
+	}
+}
+`
+
+// printThemes are the built-in stylesheets for HTML and PDF output, added
+// after baseStylesheet
+var printThemes = map[string]string{
+	// GitHub is the base stylesheet unchanged
+	"github": "",
+
+	// Academic: serif type, justified text and booktabs-style tables
+	"academic": `
+body {
+	font-family: Charter, 'Iowan Old Style', 'Palatino Linotype', Georgia, 'Times New Roman', serif;
+	font-size: 17px;
+	line-height: 1.5;
+	color: #111;
+	text-align: justify;
+	hyphens: auto;
+}
+h1, h2, h3, h4, h5, h6 {
+	font-family: inherit;
+	text-align: left;
+	border-bottom: none;
+}
+h1 { text-align: center; font-size: 1.8em; }
+h2 { font-size: 1.35em; }
+a { color: inherit; text-decoration: underline; }
+blockquote { color: #444; font-style: italic; border-left: none; padding: 0 2em; }
+table { width: auto; margin: 16px auto; }
+table th, table td { border: none; padding: 4px 12px; }
+table tr:nth-child(2n) { background-color: transparent; }
+table thead th { border-bottom: 1px solid #111; }
+table { border-top: 2px solid #111; border-bottom: 2px solid #111; }
+table.frontmatter { border: none; margin: 0 0 16px; }
+pre, code { background-color: #f5f5f5; }
+.footnotes { font-size: 0.85em; }
+`,
+
+	// Dark: light text on a dark background (GitHub dark colors)
+	"dark": `
+html {
+	background-color: #0d1117;
+	-webkit-print-color-adjust: exact;
+	print-color-adjust: exact;
+}
+body { background-color: #0d1117; color: #e6edf3; }
+h1, h2 { border-bottom-color: #30363d; }
+a { color: #4493f8; }
+code, pre { background-color: #161b22; }
+blockquote { color: #9198a1; border-left-color: #30363d; }
+table th, table td { border-color: #30363d; }
+table tr:nth-child(2n) { background-color: #161b22; }
+table.frontmatter, .footnotes, .canvas-edge-label { color: #9198a1; }
+table.frontmatter th { background-color: #161b22; }
+hr { border-top-color: #30363d; }
+mark { background-color: #bb800926; color: inherit; }
+.tag { background-color: #388bfd26; color: #4493f8; }
+.callout { background-color: color-mix(in srgb, var(--callout-color) 12%, #0d1117); }
+nav.toc { background-color: #161b22; }
+nav.toc-print { background: none; }
+nav.toc-print .toc-leader { border-bottom-color: #656c76; }
+.canvas-edge-label { stroke: #0d1117; }
+`,
+
+	// Compact: smaller type and tighter spacing, to fit more on a page
+	"compact": `
+body { font-size: 13px; line-height: 1.4; max-width: none; padding: 0; }
+h1, h2, h3, h4, h5, h6 { margin-top: 16px; margin-bottom: 8px; }
+p, ul, ol, dl, table, pre, blockquote { margin-top: 0; margin-bottom: 10px; }
+pre { padding: 8px 12px; }
+table th, table td { padding: 3px 8px; }
+hr { margin: 16px 0; }
+.callout { margin: 10px 0; padding: 4px 12px; }
+nav.toc { margin: 8px 0 16px; }
+`,
+}
+
+// PrintThemes returns the names of the built-in HTML/PDF themes
+func PrintThemes() []string {
+	names := make([]string, 0, len(printThemes))
+	for name := range printThemes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetTheme selects a built-in stylesheet by name ("" for the default)
+func (r *HTMLRenderer) SetTheme(name string) error {
+	name = strings.ToLower(name)
+	if _, ok := printThemes[name]; !ok && name != "" {
+		return fmt.Errorf("unknown theme %q (available: %s)", name, strings.Join(PrintThemes(), ", "))
+	}
+	r.theme = name
+	return nil
+}
+
+// SetCSS adds a custom stylesheet after the theme, or uses it instead of the
+// built-in styles if replace is set
+func (r *HTMLRenderer) SetCSS(css string, replace bool) {
+	r.customCSS = css
+	r.replaceCSS = replace
+}
+
+// stylesheet returns the CSS of the document: the base stylesheet, the
+// page break rules, the theme and the custom CSS
+func (r *HTMLRenderer) stylesheet() string {
+	if r.replaceCSS {
+		return printStylesheet + r.customCSS
+	}
+	return baseStylesheet + printStylesheet + printThemes[r.theme] + r.customCSS
+}
+
+// htmlTemplateData holds the fields of an --html-template document template:
+//
+//	{{.Title}}    document title
+//	{{.Content}}  rendered document
+//	{{.TOC}}      table of contents (a <nav> list of the headings)
+//	{{.Meta}}     frontmatter values by key, e.g. {{.Meta.author}}
+//	{{.Style}}    <style> element with the theme and custom CSS
+//	{{.Scripts}}  scripts that typeset math
+type htmlTemplateData struct {
+	Title   string
+	Content template.HTML
+	TOC     template.HTML
+	Meta    map[string]string
+	Style   template.HTML
+	Scripts template.HTML
+}
+
+// SetTemplate replaces the HTML document around the rendered markdown with
+// an html/template (see htmlTemplateData for its fields); "" restores the
+// default
+func (r *HTMLRenderer) SetTemplate(text string) error {
+	if text == "" {
+		r.template = nil
+		return nil
+	}
+	tmpl, err := template.New("document").Parse(text)
+	if err != nil {
+		return fmt.Errorf("invalid HTML template: %w", err)
+	}
+	r.template = tmpl
+	return nil
+}
+
+// executeTemplate renders the document with the custom HTML template
+func (r *HTMLRenderer) executeTemplate(content, toc, mathHead, mathScript string) (string, error) {
+	data := htmlTemplateData{
+		Title:   r.documentTitle(),
+		Content: template.HTML(content),
+		TOC:     template.HTML(toc),
+		Meta:    make(map[string]string),
+		Style:   template.HTML("<style>" + r.stylesheet() + "</style>" + mathHead),
+		Scripts: template.HTML(mathScript),
+	}
+	if r.frontmatter != nil {
+		for key := range r.frontmatter.Data {
+			data.Meta[key] = r.frontmatter.String(key)
+		}
+	}
+
+	var b strings.Builder
+	if err := r.template.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("invalid HTML template: %w", err)
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,235 @@
+package renderer
+
+import (
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	// Markdown link or image: [text](href) / ![alt](href), with an optional title
+	hyperlinkRegexp = regexp.MustCompile(`(!?)\[([^\[\]]*)\]\(([^()]*)\)`)
+	// Autolink: <https://example.com>, possibly already behind a hyperlink marker
+	autolinkRegexp = regexp.MustCompile(`(\x{2063}[\x{200B}\x{200C}]*)?<((?:https?|ftp|mailto):[^<>\s]+)>`)
+)
+
+// Markers for OSC 8 hyperlinks in terminal output. A link's text is wrapped
+// in linkStart and linkEnd, and linkStart is followed by the link's number in
+// the link table written in binary with linkBit0 and linkBit1. All four runes
+// are zero-width, so Glamour wraps and aligns the text as if they were not
+// there; applyHyperlinks replaces them after rendering.
+const (
+	linkStart = '\u2063'
+	linkEnd   = '\u2064'
+	linkBit0  = '\u200B'
+	linkBit1  = '\u200C'
+)
+
+// linkTable holds the targets of the hyperlinks in a document
+type linkTable struct {
+	urls []string
+	ids  map[string]int
+}
+
+// newLinkTable creates an empty link table
+func newLinkTable() *linkTable {
+	return &linkTable{ids: make(map[string]int)}
+}
+
+// marker returns the start marker for a link to target
+func (t *linkTable) marker(target string) string {
+	id, ok := t.ids[target]
+	if !ok {
+		id = len(t.urls)
+		t.urls = append(t.urls, target)
+		t.ids[target] = id
+	}
+
+	var b strings.Builder
+	b.WriteRune(linkStart)
+	for bit := 1 << (bitLength(id) - 1); bit > 0; bit >>= 1 {
+		if id&bit != 0 {
+			b.WriteRune(linkBit1)
+		} else {
+			b.WriteRune(linkBit0)
+		}
+	}
+	return b.String()
+}
+
+// bitLength returns the number of binary digits of n (1 for 0)
+func bitLength(n int) int {
+	length := 1
+	for n > 1 {
+		n >>= 1
+		length++
+	}
+	return length
+}
+
+// rewriteHyperlinks turns markdown links and autolinks on a line into
+// hyperlink markers. The link text keeps Glamour's link style, but the URL
+// after it is dropped: it is in the hyperlink instead. Images, in-document
+// anchors and inline code are left alone.
+func (lr linkResolver) rewriteHyperlinks(line string) string {
+	if lr.links == nil || !strings.ContainsAny(line, "[<") {
+		return line
+	}
+
+	// Glamour lists the links of table cells below the table, so there the
+	// text is left unstyled instead of being kept as a link
+	inTable := strings.HasPrefix(strings.TrimSpace(line), "|")
+
+	return mapOutsideCode(line, func(text string) string {
+		text = hyperlinkRegexp.ReplaceAllStringFunc(text, func(match string) string {
+			m := hyperlinkRegexp.FindStringSubmatch(match)
+			if m[1] == "!" || strings.TrimSpace(m[2]) == "" {
+				return match
+			}
+			target, ok := lr.hyperlinkTarget(m[3])
+			if !ok {
+				return match
+			}
+			if inTable {
+				return lr.links.marker(target) + m[2] + string(linkEnd)
+			}
+			// An anchor-only href makes Glamour skip the URL
+			return "[" + lr.links.marker(target) + m[2] + string(linkEnd) + "](#)"
+		})
+		return autolinkRegexp.ReplaceAllStringFunc(text, func(match string) string {
+			m := autolinkRegexp.FindStringSubmatch(match)
+			if m[1] != "" {
+				return match
+			}
+			return lr.links.marker(m[2]) + match + string(linkEnd)
+		})
+	})
+}
+
+// hyperlinkTarget returns the URL a link's href points to. URLs with a scheme
+// are kept; relative paths are resolved against the base directory into
+// file:// URLs. It reports false for in-document anchors.
+func (lr linkResolver) hyperlinkTarget(href string) (string, bool) {
+	href = strings.TrimSpace(href)
+	// Drop a "title" after the href
+	if i := strings.IndexAny(href, " \t"); i != -1 && !strings.HasPrefix(href, "<") {
+		if rest := strings.TrimSpace(href[i:]); strings.HasPrefix(rest, `"`) || strings.HasPrefix(rest, "'") {
+			href = href[:i]
+		}
+	}
+	href = strings.TrimSuffix(strings.TrimPrefix(href, "<"), ">")
+	if href == "" || strings.HasPrefix(href, "#") {
+		return "", false
+	}
+
+	// Schemes are at least two letters long, so C:\ is a path
+	if u, err := url.Parse(href); err == nil && len(u.Scheme) > 1 {
+		return href, true
+	}
+
+	path, fragment, _ := strings.Cut(href, "#")
+	if unescaped, err := url.PathUnescape(path); err == nil {
+		path = unescaped
+	}
+	if unescaped, err := url.PathUnescape(fragment); err == nil {
+		fragment = unescaped
+	}
+	path = filepath.FromSlash(path)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(lr.baseDir, path)
+	}
+	return FileURL(path, fragment), true
+}
+
+// FileURL returns the file:// URL of a local path, with an optional fragment
+func FileURL(path, fragment string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		// Windows drive letter: file:///C:/...
+		path = "/" + path
+	}
+	return (&url.URL{Scheme: "file", Path: path, Fragment: fragment}).String()
+}
+
+// Hyperlink wraps text in an OSC 8 hyperlink to target
+func Hyperlink(target, text string) string {
+	return "\x1b]8;;" + target + "\x1b\\" + text + "\x1b]8;;\x1b\\"
+}
+
+// applyHyperlinks replaces the hyperlink markers in rendered terminal output
+// with OSC 8 sequences. Links are closed at line ends and reopened after the
+// left margin of the next line, so wrapped link text stays clickable without
+// making the margin part of the link. Without a link table the markers are
+// removed.
+func applyHyperlinks(rendered string, links *linkTable) string {
+	if !strings.ContainsRune(rendered, linkStart) && !strings.ContainsRune(rendered, linkEnd) {
+		return rendered
+	}
+
+	var out strings.Builder
+	target := ""     // URL of the link being drawn
+	pending := false // The link must be reopened before the next character
+	for i := 0; i < len(rendered); {
+		// Copy escape sequences through
+		if rendered[i] == '\x1b' && i+1 < len(rendered) && rendered[i+1] == '[' {
+			j := i + 2
+			for j < len(rendered) && (rendered[j] < 0x40 || rendered[j] > 0x7e) {
+				j++
+			}
+			if j < len(rendered) {
+				j++
+			}
+			out.WriteString(rendered[i:j])
+			i = j
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(rendered[i:])
+		i += size
+		switch r {
+		case linkStart:
+			id := 0
+			for i < len(rendered) {
+				bit, bitSize := utf8.DecodeRuneInString(rendered[i:])
+				if bit != linkBit0 && bit != linkBit1 {
+					break
+				}
+				id <<= 1
+				if bit == linkBit1 {
+					id |= 1
+				}
+				i += bitSize
+			}
+			if links == nil || id >= len(links.urls) {
+				continue
+			}
+			target = links.urls[id]
+			out.WriteString("\x1b]8;;" + target + "\x1b\\")
+			pending = false
+		case linkEnd:
+			if target != "" && !pending {
+				out.WriteString("\x1b]8;;\x1b\\")
+			}
+			target = ""
+			pending = false
+		case '\n':
+			if target != "" && !pending {
+				out.WriteString("\x1b]8;;\x1b\\")
+				pending = true
+			}
+			out.WriteByte('\n')
+		default:
+			if pending && r != ' ' {
+				out.WriteString("\x1b]8;;" + target + "\x1b\\")
+				pending = false
+			}
+			out.WriteString(rendered[i-size : i])
+		}
+	}
+	return out.String()
+}
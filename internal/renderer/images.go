@@ -12,18 +12,36 @@ type ImageBlock struct {
 	AltText   string // Alt text from ![alt](path)
 	Path      string // Image file path or URL
 	Width     int    // Optional width hint in pixels (from Obsidian |width syntax)
+	Height    int    // Optional height hint in pixels (from Obsidian |widthxheight syntax)
+	Percent   int    // Optional width relative to the terminal width (from |50% syntax)
+	Remote    bool   // True if Path is an http:// or https:// URL
 	StartLine int    // Line number where image appears (1-indexed)
 	EndLine   int    // Same as StartLine for images (single line)
 }
 
 // Supported image formats for inline display
-var supportedImageFormats = []string{".png", ".jpg", ".jpeg", ".gif", ".webp"}
+var supportedImageFormats = []string{".png", ".jpg", ".jpeg", ".gif", ".webp", ".bmp", ".tif", ".tiff", ".svg"}
 
 // Image detection regex: ![alt text](path)
 var imageRegex = regexp.MustCompile(`!\[([^\]]*)\]\(([^)]+)\)`)
 
-// DetectImageBlocks finds all markdown images in the content
+// Image size spec: 400, 400x300, x300 or 50%
+var imageSizeRegex = regexp.MustCompile(`^(?:([0-9]+)?(?:x([0-9]+))?|([0-9]+)%)$`)
+
+// DetectImageBlocks finds all local markdown images in the content
 func DetectImageBlocks(content string) []ImageBlock {
+	return detectImageBlocks(content, false)
+}
+
+// DetectRemoteImageBlocks finds local images as well as images referenced by
+// http:// or https:// URLs. Remote images are not filtered by extension since
+// many image services (badges, CDNs) serve them without one.
+func DetectRemoteImageBlocks(content string) []ImageBlock {
+	return detectImageBlocks(content, true)
+}
+
+// detectImageBlocks scans content line by line for markdown images
+func detectImageBlocks(content string, includeRemote bool) []ImageBlock {
 	var blocks []ImageBlock
 	lines := strings.Split(content, "\n")
 	
@@ -37,33 +55,35 @@ func DetectImageBlocks(content string) []ImageBlock {
 			altText := match[1]
 			path := strings.TrimSpace(match[2])
 			
-			// Skip URLs (http://, https://, etc.)
-			if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+			// Remote URLs are only reported when requested
+			remote := isRemoteImage(path)
+			if remote && !includeRemote {
 				continue
 			}
 			
 			// Check if it's a supported format
-			if !isSupportedImageFormat(path) {
+			if !remote && !isSupportedImageFormat(path) {
 				continue
 			}
 			
-			// Parse width from alt text if present (format: "name|width=400")
-			width := 0
+			// Parse size from alt text if present
+			// (formats: "name|400", "name|400x300", "name|50%", "name|width=400")
 			cleanAlt := altText
-			if strings.Contains(altText, "|width=") {
-				parts := strings.SplitN(altText, "|width=", 2)
-				if len(parts) == 2 {
-					cleanAlt = parts[0]
-					if w, err := strconv.Atoi(parts[1]); err == nil {
-						width = w
-					}
+			var size imageSize
+			if idx := strings.LastIndex(altText, "|"); idx != -1 {
+				if parsed, ok := parseImageSize(strings.TrimPrefix(altText[idx+1:], "width=")); ok {
+					cleanAlt = altText[:idx]
+					size = parsed
 				}
 			}
 			
 			blocks = append(blocks, ImageBlock{
 				AltText:   cleanAlt,
 				Path:      path,
-				Width:     width,
+				Width:     size.width,
+				Height:    size.height,
+				Percent:   size.percent,
+				Remote:    remote,
 				StartLine: lineNum + 1, // 1-indexed
 				EndLine:   lineNum + 1,
 			})
@@ -73,6 +93,32 @@ func DetectImageBlocks(content string) []ImageBlock {
 	return blocks
 }
 
+// imageSize holds a parsed image size spec
+type imageSize struct {
+	width   int
+	height  int
+	percent int
+}
+
+// parseImageSize parses an Obsidian-style size spec: "400" (width),
+// "400x300" (fit inside box), "x300" (height) or "50%" (of terminal width)
+func parseImageSize(spec string) (imageSize, bool) {
+	spec = strings.TrimSpace(spec)
+	m := imageSizeRegex.FindStringSubmatch(spec)
+	if spec == "" || m == nil {
+		return imageSize{}, false
+	}
+
+	var size imageSize
+	size.width, _ = strconv.Atoi(m[1])
+	size.height, _ = strconv.Atoi(m[2])
+	size.percent, _ = strconv.Atoi(m[3])
+	if size.width == 0 && size.height == 0 && (size.percent == 0 || size.percent > 100) {
+		return imageSize{}, false
+	}
+	return size, true
+}
+
 // isSupportedImageFormat checks if the file has a supported image extension
 func isSupportedImageFormat(path string) bool {
 	ext := strings.ToLower(filepath.Ext(path))
@@ -83,3 +129,13 @@ func isSupportedImageFormat(path string) bool {
 	}
 	return false
 }
+
+// IsSVGImage reports whether the image path points to an SVG file
+func IsSVGImage(path string) bool {
+	return strings.ToLower(filepath.Ext(path)) == ".svg"
+}
+
+// isRemoteImage reports whether path is an http:// or https:// URL
+func isRemoteImage(path string) bool {
+	return strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://")
+}
@@ -0,0 +1,62 @@
+package renderer
+
+import "testing"
+
+func TestParseImageSize(t *testing.T) {
+	tests := []struct {
+		spec string
+		want imageSize
+		ok   bool
+	}{
+		{"400", imageSize{width: 400}, true},
+		{"400x300", imageSize{width: 400, height: 300}, true},
+		{"x300", imageSize{height: 300}, true},
+		{"50%", imageSize{percent: 50}, true},
+		{" 400 ", imageSize{width: 400}, true},
+		{"100%", imageSize{percent: 100}, true},
+		{"150%", imageSize{}, false},
+		{"0%", imageSize{}, false},
+		{"0", imageSize{}, false},
+		{"x", imageSize{}, false},
+		{"400x", imageSize{}, false},
+		{"50%x300", imageSize{}, false},
+		{"caption", imageSize{}, false},
+		{"", imageSize{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseImageSize(tt.spec)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseImageSize(%q) = %+v, %v, want %+v, %v", tt.spec, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestDetectImageBlocksSize(t *testing.T) {
+	tests := []struct {
+		line    string
+		alt     string
+		width   int
+		height  int
+		percent int
+	}{
+		{"![cat|400](cat.png)", "cat", 400, 0, 0},
+		{"![cat|400x300](cat.png)", "cat", 400, 300, 0},
+		{"![cat|x300](cat.png)", "cat", 0, 300, 0},
+		{"![cat|50%](cat.png)", "cat", 0, 0, 50},
+		{"![cat|width=400](cat.png)", "cat", 400, 0, 0},
+		{"![a|b](cat.png)", "a|b", 0, 0, 0},
+		{"![cat](cat.png)", "cat", 0, 0, 0},
+	}
+	for _, tt := range tests {
+		blocks := DetectImageBlocks(tt.line)
+		if len(blocks) != 1 {
+			t.Errorf("%s: got %d images, want 1", tt.line, len(blocks))
+			continue
+		}
+		b := blocks[0]
+		if b.AltText != tt.alt || b.Width != tt.width || b.Height != tt.height || b.Percent != tt.percent {
+			t.Errorf("%s: alt %q, size %dx%d, %d%%; want alt %q, size %dx%d, %d%%",
+				tt.line, b.AltText, b.Width, b.Height, b.Percent, tt.alt, tt.width, tt.height, tt.percent)
+		}
+	}
+}
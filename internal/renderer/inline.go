@@ -0,0 +1,226 @@
+package renderer
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	// Obsidian highlight: ==text==
+	highlightRegexp = regexp.MustCompile(`==([^=\s](?:[^=]*[^=\s])?)==`)
+	// Obsidian tag: #tag or #nested/tag, with at least one non-digit
+	tagRegexp = regexp.MustCompile(`(^|\s)#([\p{L}\p{N}_/-]*[\p{L}_/-][\p{L}\p{N}_/-]*)`)
+	// Obsidian block ID at the end of a line: "Paragraph ^block-id"
+	blockIDRegexp = regexp.MustCompile(`(^|\s+)\^([A-Za-z0-9-]+)\s*$`)
+)
+
+// Markers for inline styles in terminal output. Glamour does not pass ANSI
+// codes through, so highlights and tags are wrapped in private-use runes that
+// applyInlineStyles turns into colors after rendering. Each marker is one
+// column wide and is replaced by a padding space, so wrapping is unaffected.
+const (
+	highlightStart = '\uE000'
+	highlightEnd   = '\uE001'
+	tagStart       = '\uE002'
+	tagEnd         = '\uE003'
+)
+
+// Terminal colors for highlights and tag chips
+const (
+	highlightSGR = "\x1b[38;5;16;48;5;228m"
+	tagSGR       = "\x1b[38;5;153;48;5;238m"
+)
+
+// rewriteInline rewrites Obsidian highlights, tags and block IDs on a line.
+// Inline code spans are left untouched. It reports false if the line held
+// only a block ID and should be dropped.
+func (lr linkResolver) rewriteInline(line string) (string, bool) {
+	// Raw HTML is left alone ("color: #fff" is not a tag)
+	if strings.HasPrefix(strings.TrimSpace(line), "<") {
+		return line, true
+	}
+
+	// A block ID on its own line refers to the block above it
+	if m := blockIDRegexp.FindStringSubmatch(line); m != nil && strings.TrimSpace(strings.TrimLeft(line, "> ")) == "^"+m[2] {
+		if lr.html {
+			return `<div id="^` + m[2] + `"></div>`, true
+		}
+		return "", false
+	}
+
+	line = mapOutsideCode(line, func(text string) string {
+		text = highlightRegexp.ReplaceAllStringFunc(text, func(match string) string {
+			inner := highlightRegexp.FindStringSubmatch(match)[1]
+			if lr.html {
+				return "<mark>" + inner + "</mark>"
+			}
+			return string(highlightStart) + inner + string(highlightEnd)
+		})
+		return tagRegexp.ReplaceAllStringFunc(text, func(match string) string {
+			m := tagRegexp.FindStringSubmatch(match)
+			if lr.html {
+				return m[1] + `<span class="tag">#` + m[2] + `</span>`
+			}
+			return m[1] + string(tagStart) + "#" + m[2] + string(tagEnd)
+		})
+	})
+
+	// Block IDs are hidden; HTML keeps them as link anchors
+	if m := blockIDRegexp.FindStringSubmatchIndex(line); m != nil {
+		id := line[m[4]:m[5]]
+		line = line[:m[0]]
+		if lr.html {
+			line += ` <a id="^` + id + `"></a>`
+		}
+	}
+
+	return line, true
+}
+
+// mapOutsideCode applies fn to the parts of a line outside inline code spans
+func mapOutsideCode(line string, fn func(string) string) string {
+	if !strings.Contains(line, "`") {
+		return fn(line)
+	}
+
+	var out strings.Builder
+	rest := line
+	for {
+		start := strings.Index(rest, "`")
+		if start == -1 {
+			out.WriteString(fn(rest))
+			break
+		}
+		// A code span closes with a backtick run of the same length
+		ticks := len(rest[start:]) - len(strings.TrimLeft(rest[start:], "`"))
+		fence := strings.Repeat("`", ticks)
+		end := strings.Index(rest[start+ticks:], fence)
+		if end == -1 {
+			out.WriteString(fn(rest))
+			break
+		}
+		end += start + 2*ticks
+		out.WriteString(fn(rest[:start]))
+		out.WriteString(rest[start:end])
+		rest = rest[end:]
+	}
+	return out.String()
+}
+
+// stripComments removes %%comments%% from a line. Comments may span several
+// lines: inComment reports whether the line starts inside one, and the
+// returned flag whether the next line does.
+func stripComments(line string, inComment bool) (string, bool) {
+	var out strings.Builder
+	rest := line
+	for {
+		if inComment {
+			end := strings.Index(rest, "%%")
+			if end == -1 {
+				return out.String(), true
+			}
+			rest = rest[end+2:]
+			inComment = false
+			// Avoid a double space where an inline comment was
+			if strings.HasSuffix(out.String(), " ") {
+				rest = strings.TrimPrefix(rest, " ")
+			}
+			continue
+		}
+
+		// Only look for an opening %% outside inline code
+		start := indexOutsideCode(rest, "%%")
+		if start == -1 {
+			out.WriteString(rest)
+			return out.String(), false
+		}
+		out.WriteString(rest[:start])
+		rest = rest[start+2:]
+		inComment = true
+	}
+}
+
+// indexOutsideCode returns the index of the first sep outside inline code
+// spans, or -1
+func indexOutsideCode(line, sep string) int {
+	const placeholder = "\x00"
+	masked := mapOutsideCode(line, func(text string) string {
+		return strings.ReplaceAll(text, sep, placeholder+sep[1:])
+	})
+	return strings.Index(masked, placeholder)
+}
+
+// applyInlineStyles replaces the highlight and tag markers in rendered
+// terminal output with colors. Glamour resets styles between words and at
+// line ends, so the active color is re-applied after each reset.
+func applyInlineStyles(rendered string) string {
+	if !strings.ContainsAny(rendered, string([]rune{highlightStart, tagStart})) {
+		return rendered
+	}
+
+	var out strings.Builder
+	active := ""       // SGR of the highlight or tag being drawn
+	lastSGR := ""      // Glamour's most recent style, restored after a marker
+	pending := false   // active must be re-applied before the next character
+	lineStart := false // Skip the left margin of wrapped lines
+	for i := 0; i < len(rendered); {
+		// Copy escape sequences through, noting resets
+		if rendered[i] == '\x1b' && i+1 < len(rendered) && rendered[i+1] == '[' {
+			j := i + 2
+			for j < len(rendered) && (rendered[j] < 0x40 || rendered[j] > 0x7e) {
+				j++
+			}
+			if j < len(rendered) {
+				j++
+			}
+			seq := rendered[i:j]
+			out.WriteString(seq)
+			if strings.HasSuffix(seq, "m") {
+				if seq != "\x1b[0m" && seq != "\x1b[m" {
+					lastSGR = seq
+				}
+				pending = active != ""
+			}
+			i = j
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(rendered[i:])
+		switch {
+		case r == highlightStart || r == tagStart:
+			active = highlightSGR
+			if r == tagStart {
+				active = tagSGR
+			}
+			out.WriteString(active + " ")
+			pending = false
+		case r == highlightEnd || r == tagEnd:
+			if active != "" {
+				out.WriteString(" \x1b[0m" + lastSGR)
+			} else {
+				out.WriteString(" ")
+			}
+			active = ""
+			pending = false
+		case r == '\n':
+			if active != "" {
+				out.WriteString("\x1b[0m")
+				pending = true
+			}
+			lineStart = true
+			out.WriteByte('\n')
+		default:
+			if r != ' ' {
+				lineStart = false
+			}
+			if pending && !lineStart {
+				out.WriteString(active)
+				pending = false
+			}
+			out.WriteString(rendered[i : i+size])
+		}
+		i += size
+	}
+	return out.String()
+}
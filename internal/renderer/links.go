@@ -9,19 +9,72 @@ import (
 var (
 	// Obsidian wiki link: [[target]] or [[target|Label]]
 	wikiLinkRegexp = regexp.MustCompile(`\[\[([^\]|]+)(\|([^\]]+))?\]\]`)
-	// Obsidian image embed: ![[path]] or ![[path|size]]
-	imageEmbedRegexp = regexp.MustCompile(`!\[\[([^\]|]+)(\|([0-9]+))?\]\]`)
+	// Obsidian image embed: ![[path]] or ![[path|size]] where size is
+	// 400, 400x300 or 50%
+	imageEmbedRegexp = regexp.MustCompile(`!\[\[([^\]|]+)(\|([0-9]+(?:x[0-9]+)?|[0-9]+%))?\]\]`)
+	// Standard markdown link or image target: [label](href) / ![alt](href)
+	markdownLinkRegexp = regexp.MustCompile(`(\]\()([^)\s]+)(\))`)
 )
 
+// linkResolver resolves wiki-link targets against an Obsidian vault. A nil
+// vault keeps the plain "relative to the current file" mapping.
+type linkResolver struct {
+	vault        *Vault
+	sourceDir    string     // Directory of the file being processed
+	baseDir      string     // Directory hrefs are relative to (the top-level file)
+	embeds       []string   // The file being rendered ("" for stdin), then the notes being transcluded
+	html         bool       // Emit HTML for inline syntax instead of terminal markers
+	showComments bool       // Keep %%comments%% instead of hiding them
+	links        *linkTable // Targets of OSC 8 hyperlinks (nil: plain links)
+}
+
 // PreprocessLinks rewrites Obsidian-style links and embeds into standard
 // Markdown links/images so Glamour can style them normally.
 func PreprocessLinks(content string) string {
+	return preprocessLinks(content, linkResolver{embeds: []string{""}})
+}
+
+// preprocessLinks rewrites links and embeds, resolving targets through the
+// vault when one is available
+func preprocessLinks(content string, resolver linkResolver) string {
 	lines := strings.Split(content, "\n")
 	var out []string
 	inCodeFence := false
+	inComment := false
+	var mathLines []string // Lines of an open $$ block
+	inMath := false
+	region := "" // Open print-only or screen-only region
 
 	for _, line := range lines {
-		trim := strings.TrimSpace(line)
+		// Hide %%comments%%, which may span lines (code fences included)
+		if !resolver.showComments && !inCodeFence && (inComment || strings.Contains(line, "%%")) {
+			stripped, next := stripComments(line, inComment)
+			inComment = next
+			if strings.TrimSpace(stripped) == "" && strings.TrimSpace(line) != "" {
+				continue
+			}
+			line = stripped
+		}
+
+		// Fences inside blockquotes (such as transcluded notes) count too.
+		trim := strings.TrimSpace(strings.TrimLeft(line, "> "))
+
+		// Print-only and screen-only regions; the other output's are left out
+		if !inCodeFence {
+			if m := outputRegionRegexp.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+				region = ""
+				if m[1] == "" {
+					region = m[2]
+				}
+				continue
+			}
+		}
+		if region == resolver.hiddenRegion() {
+			if strings.HasPrefix(trim, "```") {
+				inCodeFence = !inCodeFence
+			}
+			continue
+		}
 
 		// Track fenced code blocks; do not rewrite inside them.
 		if strings.HasPrefix(trim, "```") {
@@ -35,62 +88,225 @@ func PreprocessLinks(content string) string {
 			continue
 		}
 
-		processed := line
-
-		// Rewrite Obsidian image embeds first (they start with ![[).
-		processed = imageEmbedRegexp.ReplaceAllStringFunc(processed, func(match string) string {
-			m := imageEmbedRegexp.FindStringSubmatch(match)
-			if len(m) < 2 {
-				return match
+		// $$ display math $$, on one line or spanning several
+		if inMath {
+			if before, ok := strings.CutSuffix(strings.TrimSpace(line), "$$"); ok {
+				out = append(out, resolver.displayMath(strings.Join(append(mathLines, before), "\n"))...)
+				inMath = false
+			} else {
+				mathLines = append(mathLines, line)
 			}
-			path := strings.TrimSpace(m[1])
-			width := strings.TrimSpace(m[3]) // Optional width from |width syntax
-
-			// Normalize path: if it's bare, assume ./path
-			if !strings.HasPrefix(path, "./") && !strings.HasPrefix(path, "/") {
-				path = "./" + path
+			continue
+		}
+		if pageBreakRegexp.MatchString(strings.TrimSpace(line)) {
+			out = append(out, resolver.pageBreak()...)
+			continue
+		}
+		// A line starting with $$ opens a block, unless more math or text
+		// follows the closing $$ ("$$a$$ and $$b$$")
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(line), "$$"); ok && !strings.Contains(strings.TrimSuffix(rest, "$$"), "$$") {
+			if tex, ok := strings.CutSuffix(rest, "$$"); ok {
+				out = append(out, resolver.displayMath(tex)...)
+			} else {
+				inMath = true
+				mathLines = []string{rest}
 			}
+			continue
+		}
 
-			// Alt text: base name without extension, optionally with width hint
-			base := filepath.Base(path)
-			if dot := strings.LastIndex(base, "."); dot != -1 {
-				base = base[:dot]
-			}
-			
-			// If width specified, encode it in the alt text for our detector to find
-			if width != "" {
-				base = base + "|width=" + width
-			}
+		// Inline transcluded notes (![[Note]]); the embedded lines are
+		// already fully processed.
+		if expanded, ok := resolver.expandNoteEmbeds(line); ok {
+			out = append(out, expanded...)
+			continue
+		}
 
-			return "![" + base + "](" + path + ")"
+		if line, ok := resolver.rewrite(line); ok {
+			out = append(out, line)
+		}
+	}
+
+	if inMath {
+		// Unclosed $$ block: keep it as written
+		out = append(out, "$$"+strings.Join(mathLines, "\n"))
+	}
+
+	// Glamour has no footnote support; HTML output uses goldmark's
+	if !resolver.html {
+		out = terminalFootnotes(out)
+	}
+
+	return strings.Join(out, "\n")
+}
+
+// rewrite applies link and inline syntax rewriting to a line. It reports
+// false if the line should be dropped.
+func (lr linkResolver) rewrite(line string) (string, bool) {
+	return lr.rewriteInline(lr.rewriteHyperlinks(lr.rewriteLine(lr.rewriteMath(line))))
+}
+
+// rewriteLine rewrites the Obsidian links and embeds on a single line
+func (lr linkResolver) rewriteLine(line string) string {
+	processed := line
+
+	// Links copied from a transcluded note are relative to that note;
+	// rebase them before any wiki-links are turned into (already rebased)
+	// markdown links.
+	if lr.sourceDir != lr.baseDir {
+		processed = markdownLinkRegexp.ReplaceAllStringFunc(processed, func(match string) string {
+			m := markdownLinkRegexp.FindStringSubmatch(match)
+			return m[1] + lr.rebase(m[2]) + m[3]
 		})
+	}
 
-		// Rewrite wiki links [[target]] / [[target|Label]].
-		processed = wikiLinkRegexp.ReplaceAllStringFunc(processed, func(match string) string {
-			m := wikiLinkRegexp.FindStringSubmatch(match)
-			if len(m) < 2 {
-				return match
-			}
-			target := strings.TrimSpace(m[1])
-			label := strings.TrimSpace(m[3])
-			if label == "" {
-				label = target
-			}
+	// Rewrite Obsidian image embeds first (they start with ![[).
+	processed = imageEmbedRegexp.ReplaceAllStringFunc(processed, func(match string) string {
+		m := imageEmbedRegexp.FindStringSubmatch(match)
+		if len(m) < 2 {
+			return match
+		}
+		path := strings.TrimSpace(m[1])
+		size := strings.TrimSpace(m[3]) // Optional size from |size syntax
+
+		if resolved, ok := lr.resolveFile(path); ok {
+			path = resolved
+		} else if !strings.HasPrefix(path, "./") && !strings.HasPrefix(path, "/") {
+			// Normalize path: if it's bare, assume ./path
+			path = lr.rebase("./" + path)
+		}
 
-			// If target already looks like a path or has an extension, keep it.
-			href := target
-			if !strings.Contains(href, "/") && !strings.Contains(href, ".") {
-				// Map note name to ./name.md
-				href = "./" + href + ".md"
-			} else if !strings.HasPrefix(href, "./") && !strings.HasPrefix(href, "/") {
-				href = "./" + href
+		// Alt text: base name without extension, optionally with width hint
+		base := filepath.Base(path)
+		if dot := strings.LastIndex(base, "."); dot != -1 {
+			base = base[:dot]
+		}
+
+		// Embeds of notes that could not be found, and of non-image files,
+		// become plain links rather than broken images
+		if !isSupportedImageFormat(path) {
+			if filepath.Ext(path) == "" {
+				path += ".md"
 			}
+			return "[" + base + "](" + path + ")"
+		}
+		
+		// If size specified, encode it in the alt text for our detector to find
+		if size != "" {
+			base = base + "|" + size
+		}
 
-			return "[" + label + "](" + href + ")"
-		})
+		// Markdown destinations cannot contain spaces; the terminal viewer
+		// reads the path as written
+		if lr.html {
+			path = strings.ReplaceAll(path, " ", "%20")
+		}
+
+		return "![" + base + "](" + path + ")"
+	})
+
+	// Rewrite wiki links [[target]] / [[target|Label]].
+	processed = wikiLinkRegexp.ReplaceAllStringFunc(processed, func(match string) string {
+		m := wikiLinkRegexp.FindStringSubmatch(match)
+		if len(m) < 2 {
+			return match
+		}
+		target := strings.TrimSpace(m[1])
+		label := strings.TrimSpace(m[3])
+		if label == "" {
+			label = target
+		}
 
-		out = append(out, processed)
+		// Split off any #heading or #^block fragment; [[#Heading]]
+		// links point into the current note.
+		name, fragment := target, ""
+		if idx := strings.Index(target, "#"); idx != -1 {
+			name, fragment = target[:idx], target[idx:]
+			// Link destinations cannot contain spaces
+			fragment = strings.ReplaceAll(fragment, " ", "%20")
+		}
+		if name == "" {
+			return "[" + label + "](" + fragment + ")"
+		}
+
+		// Resolve the note (or file) by name within the vault first.
+		if href, ok := lr.resolveLink(name); ok {
+			return "[" + label + "](" + strings.ReplaceAll(href, " ", "%20") + fragment + ")"
+		}
+
+		// If target already looks like a path or has an extension, keep it.
+		href := name
+		if !strings.Contains(href, "/") && !strings.Contains(href, ".") {
+			// Map note name to ./name.md
+			href = "./" + href + ".md"
+		} else if !strings.HasPrefix(href, "./") && !strings.HasPrefix(href, "/") {
+			href = "./" + href
+		}
+
+		return "[" + label + "](" + strings.ReplaceAll(lr.rebase(href), " ", "%20") + fragment + ")"
+	})
+
+	return processed
+}
+
+// resolveLink resolves a wiki-link target ("Note", "folder/Note",
+// "file.pdf") to an href relative to the source directory
+func (lr linkResolver) resolveLink(name string) (string, bool) {
+	if lr.vault == nil {
+		return "", false
 	}
 
-	return strings.Join(out, "\n")
-}
\ No newline at end of file
+	// Names with a non-markdown extension are attachments, but note names
+	// may contain dots too ("Release 1.2"), so fall back to notes
+	var abs string
+	var ok bool
+	if ext := filepath.Ext(name); ext != "" && !strings.EqualFold(ext, ".md") {
+		abs, ok = lr.vault.ResolveFile(name, lr.sourceDir)
+	}
+	if !ok {
+		abs, ok = lr.vault.ResolveNote(name, lr.sourceDir)
+	}
+	if !ok {
+		return "", false
+	}
+	return lr.relative(abs), true
+}
+
+// resolveFile resolves an embed target such as "img.png" or
+// "assets/img.png" to a path relative to the source directory
+func (lr linkResolver) resolveFile(target string) (string, bool) {
+	if lr.vault == nil {
+		return "", false
+	}
+	abs, ok := lr.vault.ResolveFile(target, lr.sourceDir)
+	if !ok {
+		return "", false
+	}
+	return lr.relative(abs), true
+}
+
+// rebase rewrites a relative href written in the current (possibly
+// transcluded) note so it is relative to the top-level file instead
+func (lr linkResolver) rebase(href string) string {
+	if lr.sourceDir == lr.baseDir || href == "" ||
+		strings.HasPrefix(href, "/") || strings.HasPrefix(href, "#") || strings.Contains(href, ":") {
+		return href
+	}
+	return lr.relative(filepath.Join(lr.sourceDir, filepath.FromSlash(href)))
+}
+
+// relative converts an absolute path to a ./ or ../ path from the base directory
+func (lr linkResolver) relative(abs string) string {
+	base := lr.baseDir
+	if base == "" {
+		base = "."
+	}
+	rel, err := filepath.Rel(base, abs)
+	if err != nil {
+		return filepath.ToSlash(abs)
+	}
+	rel = filepath.ToSlash(rel)
+	if !strings.HasPrefix(rel, "../") {
+		rel = "./" + rel
+	}
+	return rel
+}
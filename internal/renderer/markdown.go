@@ -2,25 +2,46 @@ package renderer
 
 import (
 	"fmt"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/glamour/styles"
 )
 
 // RenderOptions contains configuration for rendering markdown
 type RenderOptions struct {
-	Style            string // Style name: "dark", "light", "auto"
-	Width            int    // Terminal width for wrapping
-	NoMermaid        bool   // Skip mermaid diagram detection
-	MermaidMode      string // Mermaid rendering mode: "terminal", "svg", "url"
-	MermaidOutDir    string // Output directory for SVG files
-	KeepMermaidFiles bool   // Save mermaid diagram files to disk
+	Style             string // Style name: "dark", "light", "auto"
+	Width             int    // Terminal width for wrapping
+	NoMermaid         bool   // Skip mermaid diagram detection
+	MermaidMode       string // Mermaid rendering mode: "terminal", "svg", "url"
+	MermaidTheme      string // Mermaid theme: "default", "dark", "forest", "neutral"
+	MermaidOutDir     string // Output directory for SVG files
+	KeepMermaidFiles  bool   // Save mermaid diagram files to disk
+	FetchRemoteImages bool   // Download http(s) images for inline display
+	Offline           bool   // Never access the network (cached remote images only)
+	RemoteTimeout     time.Duration // Remote image download timeout (0 for the default)
+	RemoteMaxBytes    int64         // Remote image size limit in bytes (0 for the default)
+	ImageResampling   string // Image resize filter: "bilinear", "catmull-rom", "nearest"
+	NoAnimate         bool   // Show only the first frame of animated GIFs
+	ShowComments      bool   // Show Obsidian %%comments%% instead of hiding them
+	NoFrontmatter     bool   // Hide the YAML frontmatter metadata table
+	TOC               bool   // Insert a table of contents
+	Section           string // Render only the section under this heading (anchor or text)
+	Hyperlinks        bool   // Make links clickable with OSC 8 escape sequences
+	PDFTOC            bool   // Start PDF exports with a contents page with page numbers
 }
 
 // Renderer handles markdown rendering
 type Renderer struct {
-	options RenderOptions
-	glamour *glamour.TermRenderer
+	options     RenderOptions
+	glamour     *glamour.TermRenderer
+	sourcePath  string       // Absolute path of the file being rendered ("" for stdin)
+	sourceDir   string       // Directory of the file being rendered ("" for stdin)
+	vault       *Vault       // Obsidian vault containing the file, if any
+	frontmatter *Frontmatter // Metadata of the document being rendered
+	links       *linkTable   // Hyperlink targets (nil unless Hyperlinks is set)
 }
 
 // NewRenderer creates a new markdown renderer
@@ -33,46 +54,115 @@ func NewRenderer(opts RenderOptions) (*Renderer, error) {
 		opts.Style = "auto"
 	}
 
+	glamourRenderer, err := newGlamourRenderer(opts.Style, opts.Width)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Renderer{
+		options: opts,
+		glamour: glamourRenderer,
+	}
+	if opts.Hyperlinks {
+		r.links = newLinkTable()
+	}
+	return r, nil
+}
+
+// SetSourcePath tells the renderer which file is being rendered. Wiki-links
+// are then resolved relative to that file and, if it lives inside an
+// Obsidian vault, by name anywhere in the vault.
+func (r *Renderer) SetSourcePath(path string) error {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	r.sourcePath = absPath
+	r.sourceDir = filepath.Dir(absPath)
+
+	vault, err := FindVault(r.sourceDir)
+	if err != nil {
+		return err
+	}
+	r.vault = vault
+	return nil
+}
+
+// Vault returns the Obsidian vault containing the source file, or nil
+func (r *Renderer) Vault() *Vault {
+	return r.vault
+}
+
+// newGlamourRenderer creates a Glamour renderer for the given style and
+// word-wrap width
+func newGlamourRenderer(style string, width int) (*glamour.TermRenderer, error) {
 	// Create glamour renderer
 	glamourOpts := []glamour.TermRendererOption{
-		glamour.WithWordWrap(opts.Width),
+		glamour.WithWordWrap(width),
 	}
 
 	// Handle style selection
-	switch opts.Style {
+	switch style {
 	case "auto":
-		glamourOpts = append(glamourOpts, glamour.WithAutoStyle())
+		glamourOpts = append(glamourOpts, glamour.WithStyles(withStyleOverrides(autoStyle())))
 	case "dark", "light":
-		glamourOpts = append(glamourOpts, glamour.WithStylePath(opts.Style))
+		glamourOpts = append(glamourOpts, glamour.WithStyles(withStyleOverrides(*styles.DefaultStyles[style])))
 	case "notty", "clean":
 		// Use custom clean style without hash prefixes
 		glamourOpts = append(glamourOpts, glamour.WithStylesFromJSONBytes([]byte(CustomStyle)))
 	default:
 		// Try to load custom style file
-		glamourOpts = append(glamourOpts, glamour.WithStylePath(opts.Style))
+		glamourOpts = append(glamourOpts, glamour.WithStylePath(style))
 	}
 
 	glamourRenderer, err := glamour.NewTermRenderer(glamourOpts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create glamour renderer: %w", err)
 	}
-
-	return &Renderer{
-		options: opts,
-		glamour: glamourRenderer,
-	}, nil
+	return glamourRenderer, nil
 }
 
-// PreprocessLinks exposes the link preprocessing function
+// PreprocessLinks exposes the link preprocessing function, which also
+// handles Obsidian comments, highlights, tags and block IDs
 func (r *Renderer) PreprocessLinks(content string) string {
-	return PreprocessLinks(content)
+	return preprocessLinks(content, r.linkResolver())
+}
+
+// linkResolver returns the resolver for links in the file being rendered
+func (r *Renderer) linkResolver() linkResolver {
+	return linkResolver{
+		vault:        r.vault,
+		sourceDir:    r.sourceDir,
+		baseDir:      r.sourceDir,
+		embeds:       []string{r.sourcePath},
+		showComments: r.options.ShowComments,
+		links:        r.links,
+	}
+}
+
+// FileLink returns path as a hyperlink to the file when hyperlinks are
+// enabled, and unchanged otherwise
+func (r *Renderer) FileLink(path string) string {
+	if r.links == nil {
+		return path
+	}
+	return Hyperlink(FileURL(path, ""), path)
+}
+
+// DetectContentBlocks finds images and mermaid diagrams, including remote
+// images when FetchRemoteImages is enabled
+func (r *Renderer) DetectContentBlocks(content string) []ContentBlock {
+	return detectContentBlocks(content, r.options.FetchRemoteImages)
 }
 
 // Render renders markdown content to ANSI-styled terminal output
 func (r *Renderer) Render(content string) (string, error) {
 	// First preprocess links (Markdown + Obsidian-style) so Glamour can
 	// render them as normal links/images.
-	content = PreprocessLinks(content)
+	content = r.PreprocessLinks(content)
+
+	// Callouts not drawn as boxes by the viewer become titled blockquotes
+	content = PreprocessCallouts(content)
 
 	// Check for mermaid diagrams if enabled
 	if !r.options.NoMermaid {
@@ -85,7 +175,8 @@ func (r *Renderer) Render(content string) (string, error) {
 		return "", fmt.Errorf("failed to render markdown: %w", err)
 	}
 
-	return rendered, nil
+	// Color highlights and tags, and make links clickable
+	return applyHyperlinks(applyInlineStyles(rendered), r.links), nil
 }
 
 // RenderBytes renders markdown bytes to ANSI-styled terminal output
@@ -126,6 +217,7 @@ func (r *Renderer) processMermaid(content string) string {
 		// Create the indicator with clickable URLs
 		indicator := fmt.Sprintf("\n> 📊 **Mermaid Diagram** (%s)\n> \n> 🔗 View: <%s>\n> 📷 Image: <%s>\n", 
 			block.Type, liveURL, imageURL)
+		indicator = r.linkResolver().rewriteHyperlinks(indicator)
 		
 		// Calculate the actual line index (0-based)
 		insertIndex := block.StartLine - 1 + offset
@@ -0,0 +1,532 @@
+package renderer
+
+import (
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// LaTeX math ($...$ inline, $$...$$ display) is approximated with Unicode in
+// the terminal: Greek letters, operators and arrows become their symbols,
+// sub- and superscripts use Unicode sub/superscript characters where they
+// exist and fractions become a/b. HTML output keeps the TeX source in a
+// data-tex attribute for KaTeX, with the approximation as fallback text.
+
+// texSymbols maps TeX commands to Unicode symbols
+var texSymbols = map[string]string{
+	// Greek letters
+	"alpha": "α", "beta": "β", "gamma": "γ", "delta": "δ", "epsilon": "ϵ", "varepsilon": "ε",
+	"zeta": "ζ", "eta": "η", "theta": "θ", "vartheta": "ϑ", "iota": "ι", "kappa": "κ",
+	"lambda": "λ", "mu": "μ", "nu": "ν", "xi": "ξ", "omicron": "ο", "pi": "π", "varpi": "ϖ",
+	"rho": "ρ", "varrho": "ϱ", "sigma": "σ", "varsigma": "ς", "tau": "τ", "upsilon": "υ",
+	"phi": "ϕ", "varphi": "φ", "chi": "χ", "psi": "ψ", "omega": "ω",
+	"Gamma": "Γ", "Delta": "Δ", "Theta": "Θ", "Lambda": "Λ", "Xi": "Ξ", "Pi": "Π",
+	"Sigma": "Σ", "Upsilon": "Υ", "Phi": "Φ", "Psi": "Ψ", "Omega": "Ω",
+
+	// Large operators
+	"sum": "∑", "prod": "∏", "coprod": "∐", "int": "∫", "iint": "∬", "iiint": "∭",
+	"oint": "∮", "bigcup": "⋃", "bigcap": "⋂", "bigoplus": "⨁", "bigotimes": "⨂",
+
+	// Binary operators and relations
+	"pm": "±", "mp": "∓", "times": "×", "div": "÷", "cdot": "·", "ast": "∗", "star": "⋆",
+	"circ": "∘", "bullet": "∙", "oplus": "⊕", "otimes": "⊗", "cup": "∪", "cap": "∩",
+	"setminus": "∖", "wedge": "∧", "land": "∧", "vee": "∨", "lor": "∨", "neg": "¬", "lnot": "¬",
+	"leq": "≤", "le": "≤", "geq": "≥", "ge": "≥", "neq": "≠", "ne": "≠", "ll": "≪", "gg": "≫",
+	"approx": "≈", "equiv": "≡", "cong": "≅", "sim": "∼", "simeq": "≃", "propto": "∝",
+	"in": "∈", "notin": "∉", "ni": "∋", "subset": "⊂", "subseteq": "⊆", "supset": "⊃",
+	"supseteq": "⊇", "perp": "⊥", "parallel": "∥", "mid": "∣", "models": "⊨", "vdash": "⊢",
+
+	// Arrows
+	"to": "→", "rightarrow": "→", "leftarrow": "←", "gets": "←", "leftrightarrow": "↔",
+	"Rightarrow": "⇒", "Leftarrow": "⇐", "Leftrightarrow": "⇔", "implies": "⟹", "impliedby": "⟸",
+	"iff": "⟺", "mapsto": "↦", "longrightarrow": "⟶", "longleftarrow": "⟵",
+	"uparrow": "↑", "downarrow": "↓", "Uparrow": "⇑", "Downarrow": "⇓", "nearrow": "↗",
+	"searrow": "↘", "hookrightarrow": "↪", "rightleftharpoons": "⇌",
+
+	// Miscellaneous symbols
+	"infty": "∞", "partial": "∂", "nabla": "∇", "forall": "∀", "exists": "∃", "nexists": "∄",
+	"emptyset": "∅", "varnothing": "∅", "ell": "ℓ", "hbar": "ℏ", "Re": "ℜ", "Im": "ℑ",
+	"aleph": "ℵ", "angle": "∠", "triangle": "△", "degree": "°", "prime": "′",
+	"ldots": "…", "cdots": "⋯", "vdots": "⋮", "ddots": "⋱", "dots": "…",
+	"langle": "⟨", "rangle": "⟩", "lfloor": "⌊", "rfloor": "⌋", "lceil": "⌈", "rceil": "⌉",
+	"lvert": "|", "rvert": "|", "vert": "|", "Vert": "‖", "lbrace": "{", "rbrace": "}",
+	"therefore": "∴", "because": "∵", "square": "□", "checkmark": "✓",
+
+	// Spacing
+	"quad": "  ", "qquad": "    ",
+}
+
+// texFunctions are operator names written upright
+var texFunctions = map[string]bool{
+	"sin": true, "cos": true, "tan": true, "sec": true, "csc": true, "cot": true,
+	"arcsin": true, "arccos": true, "arctan": true, "sinh": true, "cosh": true, "tanh": true,
+	"log": true, "ln": true, "lg": true, "exp": true, "lim": true, "limsup": true, "liminf": true,
+	"max": true, "min": true, "sup": true, "inf": true, "det": true, "dim": true, "ker": true,
+	"gcd": true, "deg": true, "arg": true, "Pr": true, "mod": true, "bmod": true,
+}
+
+// texAccents maps accent commands to combining characters
+var texAccents = map[string]string{
+	"hat": "̂", "widehat": "̂", "bar": "̄", "overline": "̅",
+	"vec": "⃗", "dot": "̇", "ddot": "̈", "tilde": "̃", "widetilde": "̃",
+}
+
+var (
+	superscripts = map[rune]rune{
+		'0': '⁰', '1': '¹', '2': '²', '3': '³', '4': '⁴', '5': '⁵', '6': '⁶', '7': '⁷', '8': '⁸', '9': '⁹',
+		'+': '⁺', '-': '⁻', '−': '⁻', '=': '⁼', '(': '⁽', ')': '⁾', '′': '′',
+		'a': 'ᵃ', 'b': 'ᵇ', 'c': 'ᶜ', 'd': 'ᵈ', 'e': 'ᵉ', 'f': 'ᶠ', 'g': 'ᵍ', 'h': 'ʰ', 'i': 'ⁱ',
+		'j': 'ʲ', 'k': 'ᵏ', 'l': 'ˡ', 'm': 'ᵐ', 'n': 'ⁿ', 'o': 'ᵒ', 'p': 'ᵖ', 'r': 'ʳ', 's': 'ˢ',
+		't': 'ᵗ', 'u': 'ᵘ', 'v': 'ᵛ', 'w': 'ʷ', 'x': 'ˣ', 'y': 'ʸ', 'z': 'ᶻ',
+		'A': 'ᴬ', 'B': 'ᴮ', 'D': 'ᴰ', 'E': 'ᴱ', 'G': 'ᴳ', 'H': 'ᴴ', 'I': 'ᴵ', 'J': 'ᴶ', 'K': 'ᴷ',
+		'L': 'ᴸ', 'M': 'ᴹ', 'N': 'ᴺ', 'O': 'ᴼ', 'P': 'ᴾ', 'R': 'ᴿ', 'T': 'ᵀ', 'U': 'ᵁ', 'V': 'ⱽ', 'W': 'ᵂ',
+		'β': 'ᵝ', 'γ': 'ᵞ', 'δ': 'ᵟ', 'θ': 'ᶿ', 'φ': 'ᵠ', 'χ': 'ᵡ',
+	}
+	subscripts = map[rune]rune{
+		'0': '₀', '1': '₁', '2': '₂', '3': '₃', '4': '₄', '5': '₅', '6': '₆', '7': '₇', '8': '₈', '9': '₉',
+		'+': '₊', '-': '₋', '−': '₋', '=': '₌', '(': '₍', ')': '₎',
+		'a': 'ₐ', 'e': 'ₑ', 'h': 'ₕ', 'i': 'ᵢ', 'j': 'ⱼ', 'k': 'ₖ', 'l': 'ₗ', 'm': 'ₘ', 'n': 'ₙ',
+		'o': 'ₒ', 'p': 'ₚ', 'r': 'ᵣ', 's': 'ₛ', 't': 'ₜ', 'u': 'ᵤ', 'v': 'ᵥ', 'x': 'ₓ',
+		'β': 'ᵦ', 'γ': 'ᵧ', 'ρ': 'ᵨ', 'φ': 'ᵩ', 'χ': 'ᵪ',
+	}
+	doubleStruck = map[rune]string{
+		'C': "ℂ", 'H': "ℍ", 'N': "ℕ", 'P': "ℙ", 'Q': "ℚ", 'R': "ℝ", 'Z': "ℤ",
+		'0': "𝟘", '1': "𝟙",
+	}
+	vulgarFractions = map[string]string{
+		"1/2": "½", "1/3": "⅓", "2/3": "⅔", "1/4": "¼", "3/4": "¾", "1/5": "⅕",
+		"1/6": "⅙", "1/8": "⅛", "3/8": "⅜", "5/8": "⅝", "7/8": "⅞",
+	}
+)
+
+// TeXToUnicode approximates a LaTeX math expression with Unicode text
+func TeXToUnicode(tex string) string {
+	p := &texParser{src: tex}
+	out := p.parse(false)
+	// Collapse runs of spaces, keeping line breaks
+	lines := strings.Split(out, "\n")
+	for i, line := range lines {
+		lines[i] = strings.Join(strings.Fields(line), " ")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// texParser converts TeX to Unicode in a single pass
+type texParser struct {
+	src string
+	pos int
+}
+
+// parse converts up to the end of the input, or up to the closing brace of
+// a group when inGroup is set
+func (p *texParser) parse(inGroup bool) string {
+	var out strings.Builder
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch c {
+		case '}':
+			p.pos++
+			if inGroup {
+				return out.String()
+			}
+		case '{':
+			p.pos++
+			out.WriteString(p.parse(true))
+		case '\\':
+			out.WriteString(p.command())
+		case '^':
+			p.pos++
+			out.WriteString(script(p.argument(), superscripts, "^"))
+		case '_':
+			p.pos++
+			out.WriteString(script(p.argument(), subscripts, "_"))
+		case '&', '~':
+			p.pos++
+			out.WriteByte(' ')
+		case '\'':
+			p.pos++
+			out.WriteString("′")
+		case '-':
+			p.pos++
+			out.WriteString("−")
+		case '\n', '\t', '\r':
+			p.pos++
+			out.WriteByte(' ')
+		default:
+			r, size := utf8.DecodeRuneInString(p.src[p.pos:])
+			p.pos += size
+			out.WriteRune(r)
+		}
+	}
+	return out.String()
+}
+
+// argument parses the next command argument: a {group}, a command or a
+// single character
+func (p *texParser) argument() string {
+	for p.pos < len(p.src) && p.src[p.pos] == ' ' {
+		p.pos++
+	}
+	if p.pos >= len(p.src) {
+		return ""
+	}
+	switch p.src[p.pos] {
+	case '{':
+		p.pos++
+		return p.parse(true)
+	case '\\':
+		return p.command()
+	}
+	r, size := utf8.DecodeRuneInString(p.src[p.pos:])
+	p.pos += size
+	if r == '-' {
+		return "−"
+	}
+	return string(r)
+}
+
+// rawArgument returns the next {group} without converting it, for \text
+func (p *texParser) rawArgument() string {
+	for p.pos < len(p.src) && p.src[p.pos] == ' ' {
+		p.pos++
+	}
+	if p.pos >= len(p.src) || p.src[p.pos] != '{' {
+		return p.argument()
+	}
+	depth := 0
+	start := p.pos + 1
+	for ; p.pos < len(p.src); p.pos++ {
+		switch p.src[p.pos] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				p.pos++
+				return p.src[start : p.pos-1]
+			}
+		}
+	}
+	return p.src[start:]
+}
+
+// optionalArgument parses an optional [argument], if present
+func (p *texParser) optionalArgument() string {
+	if p.pos >= len(p.src) || p.src[p.pos] != '[' {
+		return ""
+	}
+	end := strings.IndexByte(p.src[p.pos:], ']')
+	if end == -1 {
+		return ""
+	}
+	inner := &texParser{src: p.src[p.pos+1 : p.pos+end]}
+	p.pos += end + 1
+	return inner.parse(false)
+}
+
+// command converts the command at the current position (at the backslash)
+func (p *texParser) command() string {
+	p.pos++ // Backslash
+	if p.pos >= len(p.src) {
+		return ""
+	}
+
+	// Single character commands: \\ \, \{ ...
+	start := p.pos
+	for p.pos < len(p.src) && isASCIILetter(p.src[p.pos]) {
+		p.pos++
+	}
+	if p.pos == start {
+		c := p.src[p.pos]
+		p.pos++
+		switch c {
+		case '\\':
+			return "\n"
+		case ',', ':', ';', ' ':
+			return " "
+		case '!':
+			return ""
+		case '|':
+			return "‖"
+		}
+		return string(c)
+	}
+
+	name := p.src[start:p.pos]
+	if symbol, ok := texSymbols[name]; ok {
+		return symbol
+	}
+	if texFunctions[name] {
+		// \sin x and \sin\theta need a space, \lim_{x} and \max(a, b) don't
+		if p.pos < len(p.src) && strings.IndexByte("_^({[ ", p.src[p.pos]) == -1 {
+			return name + " "
+		}
+		return name
+	}
+	if accent, ok := texAccents[name]; ok {
+		arg := p.argument()
+		if utf8.RuneCountInString(arg) == 1 {
+			return arg + accent
+		}
+		return arg
+	}
+
+	switch name {
+	case "frac", "dfrac", "tfrac", "cfrac":
+		num := p.argument()
+		den := p.argument()
+		return fraction(num, den)
+	case "binom", "dbinom", "tbinom":
+		n := p.argument()
+		k := p.argument()
+		return "C(" + n + ", " + k + ")"
+	case "sqrt":
+		index := p.optionalArgument()
+		arg := p.argument()
+		root := "√"
+		switch index {
+		case "":
+		case "3":
+			root = "∛"
+		case "4":
+			root = "∜"
+		default:
+			root = script(index, superscripts, "") + "√"
+		}
+		return root + parenthesize(arg)
+	case "text", "textrm", "textit", "textbf", "mbox", "textnormal":
+		return p.rawArgument()
+	case "mathrm", "mathit", "mathbf", "mathsf", "mathtt", "mathcal", "mathscr",
+		"mathfrak", "boldsymbol", "bm", "operatorname", "displaystyle", "textstyle":
+		if name == "displaystyle" || name == "textstyle" {
+			return ""
+		}
+		return p.argument()
+	case "mathbb":
+		var b strings.Builder
+		for _, r := range p.argument() {
+			if ds, ok := doubleStruck[r]; ok {
+				b.WriteString(ds)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+		return b.String()
+	case "pmod":
+		return " (mod " + p.argument() + ")"
+	case "left", "right", "big", "Big", "bigg", "Bigg", "bigl", "bigr", "Bigl", "Bigr", "middle":
+		// Delimiter sizing; "\left." is an invisible delimiter
+		if p.pos < len(p.src) && p.src[p.pos] == '.' {
+			p.pos++
+		}
+		return ""
+	case "begin", "end":
+		// Environment names (matrix, cases, aligned...) are dropped; rows
+		// and columns already become line breaks and spaces
+		p.rawArgument()
+		return ""
+	case "limits", "nolimits":
+		return ""
+	}
+	// Unknown commands are shown as written
+	return "\\" + name
+}
+
+// script renders text as a super- or subscript. If some characters have no
+// Unicode equivalent the text is written as prefix(text) instead.
+func script(text string, table map[rune]rune, prefix string) string {
+	if text == "" {
+		return ""
+	}
+	var b strings.Builder
+	for _, r := range text {
+		s, ok := table[r]
+		if !ok {
+			if utf8.RuneCountInString(text) == 1 {
+				return prefix + text
+			}
+			return prefix + "(" + text + ")"
+		}
+		b.WriteRune(s)
+	}
+	return b.String()
+}
+
+// fraction writes a fraction on one line, using a vulgar fraction glyph such
+// as ½ where one exists
+func fraction(num, den string) string {
+	num, den = strings.TrimSpace(num), strings.TrimSpace(den)
+	if glyph, ok := vulgarFractions[num+"/"+den]; ok {
+		return glyph
+	}
+	return parenthesize(num) + "/" + parenthesize(den)
+}
+
+// parenthesize wraps compound expressions in parentheses
+func parenthesize(expr string) string {
+	expr = strings.TrimSpace(expr)
+	if utf8.RuneCountInString(expr) <= 1 {
+		return expr
+	}
+	for _, r := range expr {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '.' {
+			return "(" + expr + ")"
+		}
+	}
+	return expr
+}
+
+// isASCIILetter reports whether c is an ASCII letter
+func isASCIILetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// rewriteMath replaces $...$ math, and $$...$$ math within a paragraph, on a
+// line (outside code spans)
+func (lr linkResolver) rewriteMath(line string) string {
+	if !strings.Contains(line, "$") {
+		return line
+	}
+	return mapOutsideCode(line, func(text string) string {
+		return replaceInlineMath(text, lr.inlineMath, lr.paragraphDisplayMath)
+	})
+}
+
+// replaceInlineMath finds $...$ spans following Pandoc's rules: the opening
+// $ is not followed by a space, the closing $ is not preceded by a space nor
+// followed by a digit ("$5 and $10" is not math). $$...$$ spans are passed
+// to display.
+func replaceInlineMath(text string, inline, display func(tex string) string) string {
+	var out strings.Builder
+	i := 0
+	for i < len(text) {
+		open := strings.IndexByte(text[i:], '$')
+		if open == -1 {
+			break
+		}
+		open += i
+		out.WriteString(text[i:open])
+
+		escaped := open > 0 && text[open-1] == '\\'
+		double := open+1 < len(text) && text[open+1] == '$'
+		if double && !escaped {
+			if end := strings.Index(text[open+2:], "$$"); end > 0 {
+				out.WriteString(display(text[open+2 : open+2+end]))
+				i = open + 2 + end + 2
+				continue
+			}
+		}
+		if escaped || double || open+1 >= len(text) || text[open+1] == ' ' {
+			// Not an opening $; copy $$ as a unit
+			if double {
+				out.WriteString("$$")
+				i = open + 2
+			} else {
+				out.WriteByte('$')
+				i = open + 1
+			}
+			continue
+		}
+
+		end := -1
+		for j := open + 1; j < len(text); j++ {
+			if text[j] == '\\' {
+				j++
+				continue
+			}
+			if text[j] != '$' {
+				continue
+			}
+			if text[j-1] != ' ' && (j+1 >= len(text) || !isDigit(text[j+1])) {
+				end = j
+			}
+			break
+		}
+		if end == -1 {
+			out.WriteByte('$')
+			i = open + 1
+			continue
+		}
+
+		out.WriteString(inline(text[open+1 : end]))
+		i = end + 1
+	}
+	if i < len(text) {
+		out.WriteString(text[i:])
+	}
+	return out.String()
+}
+
+// isDigit reports whether c is an ASCII digit
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// inlineMath renders inline math for the terminal or HTML
+func (lr linkResolver) inlineMath(tex string) string {
+	text := escapeMarkdown(strings.ReplaceAll(TeXToUnicode(tex), "\n", " "), lr.html)
+	if lr.html {
+		return `<span class="math math-inline" data-tex="` + html.EscapeString(tex) + `">` + text + `</span>`
+	}
+	return text
+}
+
+// paragraphDisplayMath renders $$...$$ math within a paragraph. It is set on
+// a line of its own in HTML; the terminal shows it inline.
+func (lr linkResolver) paragraphDisplayMath(tex string) string {
+	if lr.html {
+		text := escapeMarkdown(TeXToUnicode(tex), true)
+		return `<span class="math math-display" data-tex="` + html.EscapeString(tex) + `">` + text + `</span>`
+	}
+	return lr.inlineMath(tex)
+}
+
+// displayMath renders a $$...$$ block as its own paragraph: an indented
+// Unicode approximation in the terminal, or a KaTeX target in HTML
+func (lr linkResolver) displayMath(tex string) []string {
+	// Blank lines would end the HTML block around the formula
+	var texLines []string
+	for _, line := range strings.Split(tex, "\n") {
+		if strings.TrimSpace(line) != "" {
+			texLines = append(texLines, line)
+		}
+	}
+	tex = strings.Join(texLines, "\n")
+	text := TeXToUnicode(tex)
+	if lr.html {
+		return []string{"", `<div class="math math-display" data-tex="` + html.EscapeString(tex) + `">` +
+			strings.ReplaceAll(html.EscapeString(text), "\n", "<br>") + `</div>`, ""}
+	}
+
+	// Each line is a paragraph, as Glamour joins the lines of a paragraph.
+	// Em spaces indent the formula; Markdown keeps them.
+	out := []string{""}
+	for _, line := range strings.Split(text, "\n") {
+		out = append(out, "\u2003\u2003"+escapeMarkdown(line, false), "")
+	}
+	return out
+}
+
+// escapeMarkdown backslash-escapes characters Markdown would interpret. For
+// HTML output the text is HTML-escaped as well.
+func escapeMarkdown(text string, asHTML bool) string {
+	if asHTML {
+		return htmlMarkdownEscaper.Replace(html.EscapeString(text))
+	}
+	return markdownEscaper.Replace(text)
+}
+
+var (
+	// Glamour shows some escapes (\=, \~, \$) literally, so only the
+	// characters that matter are escaped
+	markdownEscaper = strings.NewReplacer(
+		"*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`,
+		"|", `\|`, "!", `\!`, "<", `\<`, ">", `\>`,
+	)
+	// htmlMarkdownEscaper leaves < and > to htmlEscape
+	htmlMarkdownEscaper = strings.NewReplacer(
+		`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`,
+		"~", `\~`, "=", `\=`, "|", `\|`, "$", `\$`, "!", `\!`,
+	)
+)
@@ -0,0 +1,74 @@
+package renderer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTeXToUnicode(t *testing.T) {
+	tests := []struct {
+		tex  string
+		want string
+	}{
+		{`e^{i\pi} + 1 = 0`, "e^(iπ) + 1 = 0"},
+		{`\sum_{i=1}^{n} i = \frac{n(n+1)}{2}`, "∑ᵢ₌₁ⁿ i = (n(n+1))/2"},
+		{`\alpha \leq \beta \Rightarrow x_1^2`, "α ≤ β ⇒ x₁²"},
+		{`x^2 + y^{10}`, "x² + y¹⁰"},
+		{`x_{\text{max}}`, "xₘₐₓ"},
+		{`x_{\text{mid}}`, "x_(mid)"},
+		{`\frac{1}{2}`, "½"},
+		{`\frac{a}{b}`, "a/b"},
+		{`\sqrt{x}`, "√x"},
+		{`\sqrt[3]{8}`, "∛8"},
+		{`\sqrt[n]{x+1}`, "ⁿ√(x+1)"},
+		{`\sin x + \cos\theta`, "sin x + cos θ"},
+		{`\lim_{x \to 0} f(x)`, "lim_(x → 0) f(x)"},
+		{`\mathbb{R}^n`, "ℝⁿ"},
+		{`\vec{v}`, "v⃗"},
+		{`\left( a \right)`, "( a )"},
+		{`\binom{n}{k}`, "C(n, k)"},
+		{`a \pmod{p}`, "a (mod p)"},
+		{`\text{if } x > 0`, "if x > 0"},
+		{`a \\ b`, "a\nb"},
+		{`\begin{cases} 1 \end{cases}`, "1"},
+		{`\unknown + 1`, `\unknown + 1`},
+		{`\{a, b\}`, "{a, b}"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := TeXToUnicode(tt.tex); got != tt.want {
+			t.Errorf("TeXToUnicode(%q) = %q, want %q", tt.tex, got, tt.want)
+		}
+	}
+}
+
+func TestReplaceInlineMath(t *testing.T) {
+	mark := func(tex string) string { return "<" + tex + ">" }
+	display := func(tex string) string { return "[" + tex + "]" }
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"a $x$ b", "a <x> b"},
+		{"$$x^2$$ and $y$", "[x^2] and <y>"},
+		{"costs $5 and $10", "costs $5 and $10"},
+		{"$ x $", "$ x $"},
+		{`\$x$`, `\$x$`},
+		{`$a\$b$`, `<a\$b>`},
+		{"unclosed $x", "unclosed $x"},
+		{"$x$2", "$x$2"},
+	}
+	for _, tt := range tests {
+		if got := replaceInlineMath(tt.text, mark, display); got != tt.want {
+			t.Errorf("replaceInlineMath(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestInlineMathHTML(t *testing.T) {
+	got := linkResolver{html: true}.inlineMath(`a < b`)
+	want := `<span class="math math-inline" data-tex="a &lt; b">`
+	if !strings.HasPrefix(got, want) {
+		t.Errorf("inlineMath = %q, want prefix %q", got, want)
+	}
+}
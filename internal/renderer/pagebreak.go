@@ -0,0 +1,33 @@
+package renderer
+
+import "regexp"
+
+var (
+	// Forced page breaks, on a line of their own:
+	// <!-- pagebreak -->, \newpage or ---pagebreak---
+	pageBreakRegexp = regexp.MustCompile(`^(?:<!--\s*pagebreak\s*-->|\\newpage|---\s*pagebreak\s*---)$`)
+	// Start and end of content shown only in print (PDF) or on screen:
+	// <!-- print-only --> ... <!-- /print-only -->
+	outputRegionRegexp = regexp.MustCompile(`^<!--\s*(/)?\s*(print|screen)-only\s*-->$`)
+)
+
+// pageBreakHTML forces a page break in PDF export
+const pageBreakHTML = `<div class="page-break"></div>`
+
+// hiddenRegion returns the kind of region left out of the output: screen-only
+// content in HTML (which is printed), print-only content in the terminal
+func (lr linkResolver) hiddenRegion() string {
+	if lr.html {
+		return "screen"
+	}
+	return "print"
+}
+
+// pageBreak returns the lines a page break marker is replaced with: a break
+// in HTML, a blank line that ends the paragraph in the terminal
+func (lr linkResolver) pageBreak() []string {
+	if lr.html {
+		return []string{"", pageBreakHTML, ""}
+	}
+	return []string{""}
+}
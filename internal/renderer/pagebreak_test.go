@@ -0,0 +1,75 @@
+package renderer
+
+import "testing"
+
+func TestPageBreaksAndOutputRegions(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		terminal string
+		html     string
+	}{
+		{
+			name:     "HTML comment break",
+			content:  "one\n<!-- pagebreak -->\ntwo",
+			terminal: "one\n\ntwo",
+			html:     "one\n\n" + pageBreakHTML + "\n\ntwo",
+		},
+		{
+			name:     "newpage",
+			content:  "one\n  \\newpage  \ntwo",
+			terminal: "one\n\ntwo",
+			html:     "one\n\n" + pageBreakHTML + "\n\ntwo",
+		},
+		{
+			name:     "dashes",
+			content:  "one\n--- pagebreak ---\ntwo",
+			terminal: "one\n\ntwo",
+			html:     "one\n\n" + pageBreakHTML + "\n\ntwo",
+		},
+		{
+			name:     "not on its own line",
+			content:  "see \\newpage here",
+			terminal: "see \\newpage here",
+			html:     "see \\newpage here",
+		},
+		{
+			name:     "in a code fence",
+			content:  "```\n<!-- pagebreak -->\n```",
+			terminal: "```\n<!-- pagebreak -->\n```",
+			html:     "```\n<!-- pagebreak -->\n```",
+		},
+		{
+			name:     "print-only",
+			content:  "a\n<!-- print-only -->\nprinted\n<!-- /print-only -->\nb",
+			terminal: "a\nb",
+			html:     "a\nprinted\nb",
+		},
+		{
+			name:     "screen-only",
+			content:  "a\n<!--screen-only-->\nshown\n<!-- / screen-only -->\nb",
+			terminal: "a\nshown\nb",
+			html:     "a\nb",
+		},
+		{
+			name:     "code fence in a hidden region",
+			content:  "<!-- print-only -->\n```\n<!-- /print-only -->\n```\n<!-- /print-only -->\nb",
+			terminal: "b",
+			html:     "```\n<!-- /print-only -->\n```\nb",
+		},
+		{
+			name:     "unclosed region",
+			content:  "a\n<!-- screen-only -->\nrest",
+			terminal: "a\nrest",
+			html:     "a",
+		},
+	}
+	for _, tt := range tests {
+		if got := preprocessLinks(tt.content, linkResolver{embeds: []string{""}}); got != tt.terminal {
+			t.Errorf("%s: terminal output = %q, want %q", tt.name, got, tt.terminal)
+		}
+		if got := preprocessLinks(tt.content, linkResolver{embeds: []string{""}, html: true}); got != tt.html {
+			t.Errorf("%s: HTML output = %q, want %q", tt.name, got, tt.html)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package renderer
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// FindSection returns the heading matching query and its subsections, up to
+// the next heading of the same or a higher level. The query is a heading
+// anchor ("deploy-steps" or "#deploy-steps") or heading text, matched
+// case-insensitively: exact anchors first, then whole text, then text
+// containing the query, then the query's letters in order. A query matching
+// several headings is an error listing the candidates.
+func FindSection(content, query string) (string, error) {
+	headings := ExtractHeadings(content)
+	matches := matchHeadings(headings, query)
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no heading matches %q (use --outline to list the headings)", query)
+	case 1:
+	default:
+		var b strings.Builder
+		fmt.Fprintf(&b, "section %q is ambiguous, it matches:", query)
+		for _, h := range matches {
+			fmt.Fprintf(&b, "\n  line %d: %s %s (#%s)", h.Line, strings.Repeat("#", h.Level), h.Text, h.ID)
+		}
+		return "", fmt.Errorf("%s", b.String())
+	}
+
+	match := matches[0]
+	lines := strings.Split(content, "\n")
+	end := len(lines)
+	for _, h := range headings {
+		if h.Line > match.Line && h.Level <= match.Level {
+			end = h.Line - 1
+			break
+		}
+	}
+	return strings.Join(lines[match.Line-1:end], "\n"), nil
+}
+
+// matchHeadings returns the headings matching query in the first tier that
+// has any matches
+func matchHeadings(headings []Heading, query string) []Heading {
+	anchor := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(query), "#"))
+	key := fuzzyKey(anchor)
+	if key == "" {
+		return nil
+	}
+
+	tiers := []func(h Heading) bool{
+		func(h Heading) bool { return h.ID == anchor },
+		func(h Heading) bool { return fuzzyKey(h.Text) == key || fuzzyKey(h.ID) == key },
+		func(h Heading) bool { return strings.Contains(fuzzyKey(h.Text), key) },
+		func(h Heading) bool { return isSubsequence(key, fuzzyKey(h.Text)) },
+	}
+	for _, matches := range tiers {
+		var found []Heading
+		for _, h := range headings {
+			if matches(h) {
+				found = append(found, h)
+			}
+		}
+		if len(found) > 0 {
+			return found
+		}
+	}
+	return nil
+}
+
+// fuzzyKey lowercases s and keeps only its letters and digits
+func fuzzyKey(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// isSubsequence reports whether the runes of sub appear in s in order
+func isSubsequence(sub, s string) bool {
+	rest := []rune(sub)
+	for _, r := range s {
+		if len(rest) == 0 {
+			break
+		}
+		if r == rest[0] {
+			rest = rest[1:]
+		}
+	}
+	return len(rest) == 0
+}
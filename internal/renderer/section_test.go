@@ -0,0 +1,90 @@
+package renderer
+
+import (
+	"strings"
+	"testing"
+)
+
+const sectionDoc = `# Guide
+
+## Installation
+
+Steps.
+
+### From source
+
+Build it.
+
+## Install-time options
+
+Flags.
+
+## Usage
+
+First.
+
+## Usage
+
+Second.
+
+` + "```" + `
+## Not a heading
+` + "```"
+
+func TestFindSection(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string // First and last line of the section
+		err   string // Error substring, if the query fails
+	}{
+		{query: "#installation", want: "## Installation … Build it."},
+		{query: "Installation", want: "## Installation … Build it."},
+		{query: "install time options", want: "## Install-time options … Flags."},
+		{query: "INSTALL_TIME_OPTIONS", want: "## Install-time options … Flags."},
+		{query: "source", want: "### From source … Build it."},
+		{query: "usage", want: "## Usage … First."},
+		{query: "usage-1", want: "## Usage … ```"},
+		{query: "guide", want: "# Guide … ```"},
+		{query: "install", err: `section "install" is ambiguous, it matches:
+  line 3: ## Installation (#installation)
+  line 11: ## Install-time options (#install-time-options)`},
+		{query: "instl", err: "is ambiguous"},
+		{query: "not a heading", err: `no heading matches "not a heading"`},
+		{query: "#", err: "no heading matches"},
+	}
+	for _, tt := range tests {
+		got, err := FindSection(sectionDoc, tt.query)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("FindSection(%q) error = %v, want %q", tt.query, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("FindSection(%q): %v", tt.query, err)
+			continue
+		}
+		lines := strings.Split(strings.TrimRight(got, "\n"), "\n")
+		if span := lines[0] + " … " + lines[len(lines)-1]; span != tt.want {
+			t.Errorf("FindSection(%q) = %q, want %q", tt.query, span, tt.want)
+		}
+	}
+}
+
+func TestIsSubsequence(t *testing.T) {
+	tests := []struct {
+		sub, s string
+		want   bool
+	}{
+		{"instl", "installation", true},
+		{"", "anything", true},
+		{"abc", "acb", false},
+		{"çd", "façade", true},
+		{"long", "lng", false},
+	}
+	for _, tt := range tests {
+		if got := isSubsequence(tt.sub, tt.s); got != tt.want {
+			t.Errorf("isSubsequence(%q, %q) = %v, want %v", tt.sub, tt.s, got, tt.want)
+		}
+	}
+}
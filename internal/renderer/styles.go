@@ -1,5 +1,37 @@
 package renderer
 
+import (
+	"os"
+
+	"github.com/charmbracelet/glamour/ansi"
+	"github.com/charmbracelet/glamour/styles"
+	"github.com/muesli/termenv"
+	"golang.org/x/term"
+)
+
+// autoStyle picks the built-in style for the terminal the way Glamour's
+// auto style does: dark or light by background, ASCII when not a terminal
+func autoStyle() ansi.StyleConfig {
+	if !term.IsTerminal(int(os.Stdout.Fd())) {
+		return styles.NoTTYStyleConfig
+	}
+	if termenv.HasDarkBackground() {
+		return styles.DarkStyleConfig
+	}
+	return styles.LightStyleConfig
+}
+
+// withStyleOverrides gives a built-in Glamour style the task list glyphs and
+// definition list layout of CustomStyle
+func withStyleOverrides(style ansi.StyleConfig) ansi.StyleConfig {
+	bold := true
+	style.Task.Ticked = "☑ "
+	style.Task.Unticked = "☐ "
+	style.DefinitionTerm.Bold = &bold
+	style.DefinitionDescription.BlockPrefix = "\n    "
+	return style
+}
+
 // CustomStyle returns a custom Glamour style JSON without heading prefixes
 // This provides a cleaner look similar to modern markdown viewers
 const CustomStyle = `{
@@ -77,8 +109,8 @@ const CustomStyle = `{
     "block_prefix": ". "
   },
   "task": {
-    "ticked": "[✓] ",
-    "unticked": "[ ] "
+    "ticked": "☑ ",
+    "unticked": "☐ "
   },
   "link": {
     "color": "30",
@@ -198,9 +230,11 @@ const CustomStyle = `{
     "row_separator": "─"
   },
   "definition_list": {},
-  "definition_term": {},
+  "definition_term": {
+    "bold": true
+  },
   "definition_description": {
-    "block_prefix": "\n🠶 "
+    "block_prefix": "\n    "
   },
   "html_block": {},
   "html_span": {}
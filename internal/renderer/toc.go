@@ -0,0 +1,404 @@
+package renderer
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/yuin/goldmark/ast"
+)
+
+var (
+	// Table of contents marker on its own line: [[TOC]] or <!-- toc -->
+	tocMarkerRegexp = regexp.MustCompile(`(?i)^\s*(\[\[toc\]\]|<!--\s*toc\s*-->)\s*$`)
+	// Setext heading underline: "===" (H1) or "---" (H2)
+	setextRegexp = regexp.MustCompile(`^ {0,3}(=+|-+)\s*$`)
+	// Inline markup removed from heading text
+	headingHTMLRegexp     = regexp.MustCompile(`<[^>]*>`)
+	headingBlockIDRegexp  = regexp.MustCompile(`\s\^[A-Za-z0-9-]+\s*$`)
+	headingLinkRegexp     = regexp.MustCompile(`!?\[([^\]]*)\]\([^)]*\)`)
+	headingWikiLinkRegexp = regexp.MustCompile(`\[\[(?:[^\]|]*\|)?([^\]]*)\]\]`)
+	headingEmphasisRegexp = regexp.MustCompile(`(^|[^\p{L}\p{N}])(?:\*+|_+|~~|==)|(?:\*+|_+|~~|==)([^\p{L}\p{N}]|$)`)
+)
+
+// tocPlaceholder marks where the table of contents goes in HTML output
+const tocPlaceholder = "<!-- mdviewer-toc -->"
+
+// Heading is a heading of a document
+type Heading struct {
+	Level int    // 1 to 6
+	Text  string // Heading text without inline markup
+	ID    string // Anchor ID, as generated by goldmark's auto heading IDs
+	Line  int    // Line number in the file, starting at 1
+}
+
+// ExtractHeadings returns the ATX and setext headings of a document, outside
+// code fences. Frontmatter is skipped, but counted in the line numbers.
+func ExtractHeadings(content string) []Heading {
+	_, body, _ := ParseFrontmatter(content)
+	offset := strings.Count(content[:len(content)-len(body)], "\n")
+
+	var headings []Heading
+	ids := make(map[string]bool)
+	add := func(level int, raw string, line int) {
+		headings = append(headings, Heading{
+			Level: level,
+			Text:  headingPlainText(raw),
+			ID:    headingSlug(headingPlainText(raw), ids),
+			Line:  offset + line + 1,
+		})
+	}
+
+	lines := strings.Split(body, "\n")
+	inCodeFence := false
+	for i, line := range lines {
+		trim := strings.TrimSpace(line)
+		if strings.HasPrefix(trim, "```") || strings.HasPrefix(trim, "~~~") {
+			inCodeFence = !inCodeFence
+			continue
+		}
+		if inCodeFence {
+			continue
+		}
+		if m := headingRegexp.FindStringSubmatch(line); m != nil {
+			add(len(m[1]), m[2], i)
+			continue
+		}
+		if i > 0 && isSetextText(lines[i-1]) {
+			if m := setextRegexp.FindStringSubmatch(line); m != nil {
+				level := 1
+				if m[1][0] == '-' {
+					level = 2
+				}
+				add(level, strings.TrimSpace(lines[i-1]), i-1)
+			}
+		}
+	}
+	return headings
+}
+
+// isSetextText reports whether a line can be the text of a setext heading
+func isSetextText(line string) bool {
+	trim := strings.TrimSpace(line)
+	if trim == "" || strings.HasPrefix(line, "    ") {
+		return false
+	}
+	for _, prefix := range []string{"#", ">", "|", "- ", "* ", "+ ", "```", "~~~", "<"} {
+		if strings.HasPrefix(trim, prefix) {
+			return false
+		}
+	}
+	return !setextRegexp.MatchString(line)
+}
+
+// headingSlug generates a heading ID the way goldmark does: ASCII letters and
+// digits are kept (lowercased), spaces, dashes and underscores become dashes
+// and everything else is dropped. Duplicates get a -1, -2... suffix.
+func headingSlug(raw string, ids map[string]bool) string {
+	var b strings.Builder
+	raw = strings.TrimSpace(raw)
+	for i := 0; i < len(raw); i++ {
+		c := raw[i]
+		switch {
+		case c >= 0x80:
+			// Multi-byte characters are skipped
+		case c >= 'A' && c <= 'Z':
+			b.WriteByte(c + 'a' - 'A')
+		case c >= 'a' && c <= 'z', c >= '0' && c <= '9':
+			b.WriteByte(c)
+		case c == ' ', c == '\t', c == '-', c == '_':
+			b.WriteByte('-')
+		}
+	}
+	slug := b.String()
+	if slug == "" {
+		slug = "heading"
+	}
+	if !ids[slug] {
+		ids[slug] = true
+		return slug
+	}
+	for i := 1; ; i++ {
+		candidate := fmt.Sprintf("%s-%d", slug, i)
+		if !ids[candidate] {
+			ids[candidate] = true
+			return candidate
+		}
+	}
+}
+
+// headingPlainText removes links, emphasis, code, HTML tags and Obsidian
+// comments and block IDs from heading text, and shows math as Unicode. It
+// gives the same text for a heading as written and as preprocessed for HTML,
+// so heading IDs match between the terminal and PDF.
+func headingPlainText(raw string) string {
+	text, _ := stripComments(raw, false)
+	text = headingBlockIDRegexp.ReplaceAllString(text, "")
+	text = replaceInlineMath(text, TeXToUnicode, TeXToUnicode)
+	text = html.UnescapeString(headingHTMLRegexp.ReplaceAllString(text, ""))
+	text = headingLinkRegexp.ReplaceAllString(text, "$1")
+	text = headingWikiLinkRegexp.ReplaceAllString(text, "$1")
+	text = strings.ReplaceAll(text, "`", "")
+	// Twice, as adjacent delimiters share the character between them
+	for range 2 {
+		text = headingEmphasisRegexp.ReplaceAllString(text, "$1$2")
+	}
+	return strings.TrimSpace(text)
+}
+
+// headingIDs generates the heading IDs of HTML output from the heading's
+// plain text, as ExtractHeadings does. Explicit {#id} attributes are kept.
+type headingIDs struct {
+	used map[string]bool
+}
+
+func newHeadingIDs() *headingIDs {
+	return &headingIDs{used: make(map[string]bool)}
+}
+
+func (h *headingIDs) Generate(value []byte, kind ast.NodeKind) []byte {
+	return []byte(headingSlug(headingPlainText(string(value)), h.used))
+}
+
+func (h *headingIDs) Put(value []byte) {
+	h.used[string(value)] = true
+}
+
+// tocHeadings returns the headings listed in a table of contents. A single
+// H1 is the document title and is left out.
+func tocHeadings(headings []Heading) []Heading {
+	h1 := 0
+	for _, h := range headings {
+		if h.Level == 1 {
+			h1++
+		}
+	}
+	if h1 != 1 {
+		return headings
+	}
+	var out []Heading
+	for _, h := range headings {
+		if h.Level != 1 {
+			out = append(out, h)
+		}
+	}
+	return out
+}
+
+// insertTOC puts toc at the [[TOC]] / <!-- toc --> markers of a document, or
+// after its first H1 (at the top if it has none) when there are no markers.
+// With an empty toc the markers are removed.
+func insertTOC(content, toc string) string {
+	lines := strings.Split(content, "\n")
+	var out []string
+	found := false
+	firstH1 := -1
+	inCodeFence := false
+	for i, line := range lines {
+		trim := strings.TrimSpace(line)
+		if strings.HasPrefix(trim, "```") || strings.HasPrefix(trim, "~~~") {
+			inCodeFence = !inCodeFence
+		}
+		if !inCodeFence && tocMarkerRegexp.MatchString(line) {
+			found = true
+			if toc != "" {
+				out = append(out, "", toc, "")
+			}
+			continue
+		}
+		if !inCodeFence && firstH1 == -1 {
+			if m := headingRegexp.FindStringSubmatch(line); m != nil && len(m[1]) == 1 {
+				firstH1 = len(out)
+			} else if i > 0 && strings.HasPrefix(trim, "=") && setextRegexp.MatchString(line) && isSetextText(lines[i-1]) {
+				firstH1 = len(out)
+			}
+		}
+		out = append(out, line)
+	}
+
+	if found || toc == "" {
+		return strings.Join(out, "\n")
+	}
+	at := firstH1 + 1
+	result := append([]string{}, out[:at]...)
+	result = append(result, "", toc, "")
+	result = append(result, out[at:]...)
+	return strings.Join(result, "\n")
+}
+
+// InsertTOC adds a table of contents to a document when TOC is enabled, and
+// removes [[TOC]] / <!-- toc --> markers otherwise
+func (r *Renderer) InsertTOC(content string) string {
+	if !r.options.TOC {
+		return insertTOC(content, "")
+	}
+	headings := tocHeadings(ExtractHeadings(content))
+	if len(headings) == 0 {
+		return insertTOC(content, "")
+	}
+	return insertTOC(content, tocMarkdown(headings))
+}
+
+// tocMarkdown formats headings as a nested list under a "Contents" title
+func tocMarkdown(headings []Heading) string {
+	minLevel := tocMinLevel(headings)
+	var b strings.Builder
+	b.WriteString("**Contents**\n\n")
+	for _, h := range headings {
+		b.WriteString(strings.Repeat("  ", h.Level-minLevel))
+		b.WriteString("- " + h.Text + "\n")
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// tocMinLevel returns the level of the outermost headings
+func tocMinLevel(headings []Heading) int {
+	level := 6
+	for _, h := range headings {
+		level = min(level, h.Level)
+	}
+	return level
+}
+
+// RenderOutline draws the heading tree of a document with line numbers
+func (r *Renderer) RenderOutline(content string) string {
+	headings := ExtractHeadings(content)
+	if len(headings) == 0 {
+		return "(no headings)\n"
+	}
+
+	numberWidth := len(fmt.Sprint(headings[len(headings)-1].Line))
+	numberStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+	minLevel := tocMinLevel(headings)
+
+	var b strings.Builder
+	for _, h := range headings {
+		number := numberStyle.Render(fmt.Sprintf("%*d", numberWidth, h.Line))
+		text := h.Text
+		if h.Level == minLevel {
+			text = lipgloss.NewStyle().Bold(true).Render(text)
+		}
+		fmt.Fprintf(&b, "%s  %s%s\n", number, strings.Repeat("  ", h.Level-minLevel), text)
+	}
+	return b.String()
+}
+
+// astHeadings collects the top-level headings of a parsed document with the
+// IDs goldmark assigned to them
+func astHeadings(doc ast.Node, source []byte) []Heading {
+	var headings []Heading
+	for n := doc.FirstChild(); n != nil; n = n.NextSibling() {
+		heading, ok := n.(*ast.Heading)
+		if !ok {
+			continue
+		}
+		id, _ := heading.AttributeString("id")
+		idBytes, _ := id.([]byte)
+		headings = append(headings, Heading{
+			Level: heading.Level,
+			Text:  strings.TrimSpace(astText(heading, source)),
+			ID:    string(idBytes),
+		})
+	}
+	return headings
+}
+
+// astText returns the text of an inline node tree
+func astText(n ast.Node, source []byte) string {
+	var b bytes.Buffer
+	_ = ast.Walk(n, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch t := node.(type) {
+		case *ast.Text:
+			b.Write(t.Segment.Value(source))
+			if t.SoftLineBreak() || t.HardLineBreak() {
+				b.WriteByte(' ')
+			}
+		case *ast.String:
+			b.Write(t.Value)
+		}
+		return ast.WalkContinue, nil
+	})
+	return b.String()
+}
+
+// tocHTML formats headings as a nested list of links to their anchors. With
+// pageNumbers it is a printed contents page, with dot leaders to page numbers
+// that the PDF exporter fills in.
+func tocHTML(headings []Heading, pageNumbers bool) string {
+	if len(headings) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	if pageNumbers {
+		b.WriteString(`<nav class="toc toc-print">`)
+	} else {
+		b.WriteString(`<nav class="toc">`)
+	}
+	b.WriteString("\n<p class=\"toc-title\">Contents</p>\n")
+	level := tocMinLevel(headings) - 1
+	for _, h := range headings {
+		for ; level < h.Level; level++ {
+			b.WriteString("<ul>\n")
+		}
+		for ; level > h.Level; level-- {
+			b.WriteString("</ul>\n")
+		}
+		if pageNumbers {
+			fmt.Fprintf(&b, "<li><a href=\"#%s\"><span class=\"toc-text\">%s</span><span class=\"toc-leader\"></span><span class=\"toc-page\"></span></a></li>\n",
+				html.EscapeString(h.ID), html.EscapeString(h.Text))
+		} else {
+			fmt.Fprintf(&b, "<li><a href=\"#%s\">%s</a></li>\n", html.EscapeString(h.ID), html.EscapeString(h.Text))
+		}
+	}
+	for ; level >= tocMinLevel(headings); level-- {
+		b.WriteString("</ul>\n")
+	}
+	b.WriteString("</nav>\n")
+	return b.String()
+}
+
+// resolveAnchorLinks points #fragment links written with heading text, such
+// as [[#Two Words]] or [text](#Two-Words), at the ID of the heading. Links
+// that already match an ID, or match no heading, are left alone.
+func resolveAnchorLinks(doc ast.Node, headings []Heading) {
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		link, ok := n.(*ast.Link)
+		if !entering || !ok || !bytes.HasPrefix(link.Destination, []byte("#")) {
+			return ast.WalkContinue, nil
+		}
+		if h, ok := findHeading(string(link.Destination[1:]), headings); ok {
+			link.Destination = []byte("#" + h.ID)
+		}
+		return ast.WalkContinue, nil
+	})
+}
+
+// findHeading returns the heading a link fragment names: by ID, by the ID
+// its text would get, or by fuzzy heading text
+func findHeading(fragment string, headings []Heading) (Heading, bool) {
+	for _, h := range headings {
+		if h.ID == fragment {
+			return h, true
+		}
+	}
+	if unescaped, err := url.PathUnescape(fragment); err == nil {
+		fragment = unescaped
+	}
+
+	slug := headingSlug(fragment, make(map[string]bool))
+	key := fuzzyKey(fragment)
+	for _, h := range headings {
+		if h.ID == slug || (key != "" && fuzzyKey(h.Text) == key) {
+			return h, true
+		}
+	}
+	return Heading{}, false
+}
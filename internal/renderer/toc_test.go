@@ -0,0 +1,93 @@
+package renderer
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/parser"
+)
+
+var headingIDRegexp = regexp.MustCompile(`<h[1-6] id="([^"]*)"`)
+
+func TestHeadingSlugMatchesGoldmark(t *testing.T) {
+	headings := []string{
+		"Getting Started",
+		"API v2.0 (beta)",
+		"snake_case and kebab-case",
+		"  Padded  ",
+		"Café Olé",
+		"日本語",
+		"C++ & Go!",
+		"Getting Started",
+		"Getting Started",
+	}
+
+	md := goldmark.New(goldmark.WithParserOptions(parser.WithAutoHeadingID()))
+	var buf bytes.Buffer
+	source := "# " + strings.Join(headings, "\n\n# ")
+	if err := md.Convert([]byte(source), &buf); err != nil {
+		t.Fatalf("Convert: %v", err)
+	}
+	var want []string
+	for _, m := range headingIDRegexp.FindAllStringSubmatch(buf.String(), -1) {
+		want = append(want, m[1])
+	}
+
+	if len(want) != len(headings) {
+		t.Fatalf("goldmark generated %d IDs, want %d", len(want), len(headings))
+	}
+	ids := make(map[string]bool)
+	for i, heading := range headings {
+		if got := headingSlug(heading, ids); got != want[i] {
+			t.Errorf("headingSlug(%q) = %q, want goldmark's %q", heading, got, want[i])
+		}
+	}
+}
+
+func TestHeadingPlainText(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"Plain", "Plain"},
+		{"**Bold** and _italic_", "Bold and italic"},
+		{"snake_case_name", "snake_case_name"},
+		{"Use `go test`", "Use go test"},
+		{"[Link](https://example.com) text", "Link text"},
+		{"See [[Other Note|alias]] and [[Plain]]", "See alias and Plain"},
+		{"Heading ^block-1", "Heading"},
+		{"Visible %%hidden%% text", "Visible text"},
+		{"<em>HTML</em> &amp; entities", "HTML & entities"},
+		{"Euler $e^{i\\pi}$", "Euler e^(iπ)"},
+		{"==Marked== ~~struck~~", "Marked struck"},
+	}
+	for _, tt := range tests {
+		if got := headingPlainText(tt.raw); got != tt.want {
+			t.Errorf("headingPlainText(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestHeadingIDsMatchHTML(t *testing.T) {
+	content := "# Intro\n\n## **Setup** `go`\n\n## [Link](x.md) heading\n\n## Math $\\alpha$\n\n## Intro\n\n## Note %%draft%%"
+	var want []string
+	for _, h := range ExtractHeadings(content) {
+		want = append(want, h.ID)
+	}
+
+	out, err := NewHTMLRenderer().RenderToHTML(content)
+	if err != nil {
+		t.Fatalf("RenderToHTML: %v", err)
+	}
+	var got []string
+	for _, m := range headingIDRegexp.FindAllStringSubmatch(out, -1) {
+		got = append(got, m[1])
+	}
+
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("HTML heading IDs = %q, terminal heading IDs = %q", got, want)
+	}
+}
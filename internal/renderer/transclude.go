@@ -0,0 +1,254 @@
+package renderer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// maxTransclusionDepth limits how deeply ![[Note]] embeds may nest
+const maxTransclusionDepth = 5
+
+var (
+	// Obsidian note embed: ![[Note]], ![[Note#Heading]] or ![[Note#^block-id]]
+	noteEmbedRegexp = regexp.MustCompile(`!\[\[([^\]|]+)(\|([^\]]+))?\]\]`)
+	// ATX heading: "## Title"
+	headingRegexp = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+)
+
+// expandNoteEmbeds replaces note embeds on a line with the (recursively
+// processed) content of the embedded note, framed as a blockquote. Embeds of
+// images and other files are left for rewriteLine. It reports false if the
+// line contains no note embeds.
+func (lr linkResolver) expandNoteEmbeds(line string) ([]string, bool) {
+	matches := noteEmbedRegexp.FindAllStringSubmatchIndex(line, -1)
+	if len(matches) == 0 {
+		return nil, false
+	}
+
+	var out []string
+	found := false
+	last := 0
+	for _, m := range matches {
+		target := strings.TrimSpace(line[m[2]:m[3]])
+		name, fragment := target, ""
+		if idx := strings.Index(target, "#"); idx != -1 {
+			name, fragment = target[:idx], target[idx+1:]
+		}
+
+		path, ok := lr.resolveNoteEmbed(name)
+		if !ok {
+			continue
+		}
+		found = true
+
+		// Text before the embed stays on its own line
+		if before := line[last:m[0]]; strings.TrimSpace(before) != "" {
+			if rewritten, ok := lr.rewrite(before); ok {
+				out = append(out, rewritten)
+			}
+		}
+		out = append(out, lr.transclude(path, name, fragment)...)
+		last = m[1]
+	}
+
+	if !found {
+		return nil, false
+	}
+	if rest := line[last:]; strings.TrimSpace(rest) != "" {
+		if rewritten, ok := lr.rewrite(strings.TrimLeft(rest, " \t")); ok {
+			out = append(out, rewritten)
+		}
+	}
+	return out, true
+}
+
+// resolveNoteEmbed returns the absolute path of the note named by an embed,
+// or false if the embed targets an image or other attachment
+func (lr linkResolver) resolveNoteEmbed(name string) (string, bool) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", false
+	}
+
+	ext := strings.ToLower(filepath.Ext(name))
+	if lr.vault != nil {
+		// Attachments with a real extension win over notes with dots in
+		// their names ("Release 1.2")
+		if ext != "" && ext != ".md" {
+			if _, ok := lr.vault.ResolveFile(name, lr.sourceDir); ok {
+				return "", false
+			}
+		}
+		return lr.vault.ResolveNote(name, lr.sourceDir)
+	}
+
+	if isSupportedImageFormat(name) {
+		return "", false
+	}
+	if ext != ".md" {
+		name += ".md"
+	}
+	path := filepath.Join(lr.dir(), filepath.FromSlash(name))
+	if info, err := os.Stat(path); err != nil || info.IsDir() {
+		return "", false
+	}
+	return path, true
+}
+
+// transclude returns the framed, processed lines of an embedded note. The
+// fragment selects a heading section ("Heading") or block ("^block-id").
+func (lr linkResolver) transclude(path, name, fragment string) []string {
+	title := name
+	if fragment != "" {
+		title = name + " › " + strings.TrimPrefix(fragment, "^")
+	}
+
+	for _, embedded := range lr.embeds {
+		if embedded == path {
+			return frameTransclusion(title, []string{fmt.Sprintf("⚠️ *Embed of %q skipped: circular reference*", name)})
+		}
+	}
+	if len(lr.embeds) > maxTransclusionDepth {
+		return frameTransclusion(title, []string{fmt.Sprintf("⚠️ *Embed of %q skipped: nested more than %d levels*", name, maxTransclusionDepth)})
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return frameTransclusion(title, []string{fmt.Sprintf("⚠️ *Embed of %q failed: %v*", name, err)})
+	}
+	content := strings.ReplaceAll(string(raw), "\r\n", "\n")
+
+	// Embeds never show the embedded note's properties
+	_, content, _ = ParseFrontmatter(content)
+
+	switch {
+	case strings.HasPrefix(fragment, "^"):
+		block, ok := extractBlock(content, fragment[1:])
+		if !ok {
+			return frameTransclusion(title, []string{fmt.Sprintf("⚠️ *Block ^%s not found in %q*", fragment[1:], name)})
+		}
+		content = block
+	case fragment != "":
+		section, ok := extractSection(content, fragment)
+		if !ok {
+			return frameTransclusion(title, []string{fmt.Sprintf("⚠️ *Heading %q not found in %q*", fragment, name)})
+		}
+		content = section
+	}
+
+	// Process the embedded note relative to its own folder, keeping hrefs
+	// relative to the top-level file
+	child := lr
+	child.sourceDir = filepath.Dir(path)
+	child.embeds = append(append([]string(nil), lr.embeds...), path)
+	processed := preprocessLinks(strings.Trim(content, "\n"), child)
+
+	return frameTransclusion(title, strings.Split(processed, "\n"))
+}
+
+// frameTransclusion wraps embedded lines in a titled blockquote so they are
+// set apart both in the terminal and in HTML/PDF output
+func frameTransclusion(title string, lines []string) []string {
+	out := []string{"", "> **📄 " + title + "**", ">"}
+	for _, line := range lines {
+		if line == "" {
+			out = append(out, ">")
+		} else {
+			out = append(out, "> "+line)
+		}
+	}
+	return append(out, "")
+}
+
+// extractSection returns the heading matching name and everything up to the
+// next heading of the same or a higher level. Obsidian's nested form
+// "Parent#Child" matches the last heading in the chain.
+func extractSection(content, name string) (string, bool) {
+	if idx := strings.LastIndex(name, "#"); idx != -1 {
+		name = name[idx+1:]
+	}
+	name = strings.TrimSpace(name)
+
+	lines := strings.Split(content, "\n")
+	start, level := -1, 0
+	inCodeFence := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCodeFence = !inCodeFence
+			continue
+		}
+		if inCodeFence {
+			continue
+		}
+		m := headingRegexp.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		if start == -1 {
+			if strings.EqualFold(strings.TrimSpace(m[2]), name) {
+				start, level = i, len(m[1])
+			}
+			continue
+		}
+		if len(m[1]) <= level {
+			return strings.Join(lines[start:i], "\n"), true
+		}
+	}
+
+	if start == -1 {
+		return "", false
+	}
+	return strings.Join(lines[start:], "\n"), true
+}
+
+// extractBlock returns the paragraph (or list item, quote, table...) marked
+// with ^id, without the marker. A marker on its own line refers to the
+// block directly above it.
+func extractBlock(content, id string) (string, bool) {
+	marker := "^" + id
+	lines := strings.Split(content, "\n")
+	inCodeFence := false
+	for i, line := range lines {
+		trim := strings.TrimSpace(line)
+		if strings.HasPrefix(trim, "```") {
+			inCodeFence = !inCodeFence
+			continue
+		}
+		if inCodeFence || (trim != marker && !strings.HasSuffix(trim, " "+marker)) {
+			continue
+		}
+
+		end := i
+		if trim == marker {
+			// Skip blank lines between the block and its marker
+			for end > 0 && strings.TrimSpace(lines[end-1]) == "" {
+				end--
+			}
+		} else {
+			lines[i] = strings.TrimSuffix(strings.TrimRight(line, " \t"), " "+marker)
+			end = i + 1
+		}
+
+		start := end
+		for start > 0 && strings.TrimSpace(lines[start-1]) != "" {
+			start--
+		}
+		if start == end {
+			return "", false
+		}
+		return strings.Join(lines[start:end], "\n"), true
+	}
+
+	return "", false
+}
+
+// dir returns the directory relative links are resolved against
+func (lr linkResolver) dir() string {
+	if lr.sourceDir == "" {
+		return "."
+	}
+	return lr.sourceDir
+}
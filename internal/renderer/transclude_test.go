@@ -0,0 +1,128 @@
+package renderer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTranscludeLimits(t *testing.T) {
+	// A chain of notes one level deeper than the limit
+	chain := map[string]string{}
+	for i := 0; i <= maxTransclusionDepth+1; i++ {
+		chain[fmt.Sprintf("N%d.md", i)] = fmt.Sprintf("level %d\n\n![[N%d]]", i, i+1)
+	}
+
+	tests := []struct {
+		name  string
+		files map[string]string
+		root  string
+		want  []string
+		skip  string // Text that must not appear
+	}{
+		{
+			name:  "self embed",
+			files: map[string]string{"A.md": "a\n\n![[A]]"},
+			root:  "A.md",
+			want:  []string{`Embed of "A" skipped: circular reference`},
+		},
+		{
+			name:  "cycle",
+			files: map[string]string{"A.md": "a\n\n![[B]]", "B.md": "b\n\n![[A]]"},
+			root:  "A.md",
+			want:  []string{"> b", `Embed of "A" skipped: circular reference`},
+		},
+		{
+			name:  "same note twice",
+			files: map[string]string{"A.md": "![[B]]\n\n![[B]]", "B.md": "b"},
+			root:  "A.md",
+			skip:  "circular reference",
+		},
+		{
+			name:  "depth",
+			files: chain,
+			root:  "N0.md",
+			want:  []string{fmt.Sprintf("level %d", maxTransclusionDepth), fmt.Sprintf("nested more than %d levels", maxTransclusionDepth)},
+			skip:  fmt.Sprintf("level %d", maxTransclusionDepth+1),
+		},
+		{
+			name:  "missing heading",
+			files: map[string]string{"A.md": "![[B#Nope]]", "B.md": "# Yes\n\nb"},
+			root:  "A.md",
+			want:  []string{`Heading "Nope" not found in "B"`},
+		},
+		{
+			name:  "frontmatter hidden",
+			files: map[string]string{"A.md": "![[B]]", "B.md": "---\ntags: [x]\n---\nbody"},
+			root:  "A.md",
+			want:  []string{"> body"},
+			skip:  "tags",
+		},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		for name, content := range tt.files {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		root := filepath.Join(dir, tt.root)
+		lr := linkResolver{sourceDir: dir, baseDir: dir, embeds: []string{root}}
+		got := preprocessLinks(tt.files[tt.root], lr)
+		for _, want := range tt.want {
+			if !strings.Contains(got, want) {
+				t.Errorf("%s: output does not contain %q:\n%s", tt.name, want, got)
+			}
+		}
+		if tt.skip != "" && strings.Contains(got, tt.skip) {
+			t.Errorf("%s: output contains %q:\n%s", tt.name, tt.skip, got)
+		}
+	}
+}
+
+func TestExtractSection(t *testing.T) {
+	content := "# Title\n\nintro\n\n## Setup\n\nsteps\n\n### Details\n\nmore\n\n```\n# not a heading\n```\n\n## Usage\n\nrun it"
+	tests := []struct {
+		name string
+		want string
+		ok   bool
+	}{
+		{"Setup", "## Setup\n\nsteps\n\n### Details\n\nmore\n\n```\n# not a heading\n```\n", true},
+		{"setup", "## Setup\n\nsteps\n\n### Details\n\nmore\n\n```\n# not a heading\n```\n", true},
+		{"Details", "### Details\n\nmore\n\n```\n# not a heading\n```\n", true},
+		{"Setup#Details", "### Details\n\nmore\n\n```\n# not a heading\n```\n", true},
+		{"Usage", "## Usage\n\nrun it", true},
+		{"Title", content, true},
+		{"not a heading", "", false},
+		{"Missing", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := extractSection(content, tt.name)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("extractSection(%q) = %q, %v, want %q, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestExtractBlock(t *testing.T) {
+	content := "first paragraph\nstill first ^p1\n\n- item one\n- item two ^item\n\n> quoted\n> text\n\n^quote\n\n```\ncode ^code\n```"
+	tests := []struct {
+		id   string
+		want string
+		ok   bool
+	}{
+		{"p1", "first paragraph\nstill first", true},
+		{"item", "- item one\n- item two", true},
+		{"quote", "> quoted\n> text", true},
+		{"code", "", false},
+		{"missing", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := extractBlock(content, tt.id)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("extractBlock(%q) = %q, %v, want %q, %v", tt.id, got, ok, tt.want, tt.ok)
+		}
+	}
+}
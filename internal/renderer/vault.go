@@ -0,0 +1,209 @@
+package renderer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Vault is an Obsidian vault: a directory tree with a .obsidian folder at its
+// root. Notes and attachments are indexed by name so wiki-links can be
+// resolved the way Obsidian does, regardless of the linking note's location.
+type Vault struct {
+	Root             string              // Absolute path of the vault root
+	AttachmentFolder string              // attachmentFolderPath from .obsidian/app.json
+	notes            map[string][]string // lowercase note name (no .md) -> vault-relative paths
+	files            map[string][]string // lowercase file name -> vault-relative paths
+	paths            map[string]string   // lowercase vault-relative path -> vault-relative path
+	warned           map[string]bool     // ambiguous links already reported
+}
+
+// obsidianAppConfig is the subset of .obsidian/app.json used by mdviewer
+type obsidianAppConfig struct {
+	AttachmentFolderPath string `json:"attachmentFolderPath"`
+}
+
+// FindVault walks up from dir looking for a folder containing .obsidian and
+// indexes the vault. It returns nil if dir is not inside a vault.
+func FindVault(dir string) (*Vault, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		if info, err := os.Stat(filepath.Join(abs, ".obsidian")); err == nil && info.IsDir() {
+			return OpenVault(abs)
+		}
+		parent := filepath.Dir(abs)
+		if parent == abs {
+			return nil, nil
+		}
+		abs = parent
+	}
+}
+
+// OpenVault indexes the vault rooted at root
+func OpenVault(root string) (*Vault, error) {
+	v := &Vault{
+		Root:   root,
+		notes:  make(map[string][]string),
+		files:  make(map[string][]string),
+		paths:  make(map[string]string),
+		warned: make(map[string]bool),
+	}
+
+	// The attachment folder setting is optional; Obsidian defaults to the root
+	if raw, err := os.ReadFile(filepath.Join(root, ".obsidian", "app.json")); err == nil {
+		var cfg obsidianAppConfig
+		if err := json.Unmarshal(raw, &cfg); err == nil {
+			v.AttachmentFolder = cfg.AttachmentFolderPath
+		}
+	}
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		// Obsidian ignores hidden files and folders (.obsidian, .git, .trash)
+		if path != root && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		name := strings.ToLower(d.Name())
+
+		v.paths[strings.ToLower(rel)] = rel
+		v.files[name] = append(v.files[name], rel)
+		if strings.EqualFold(filepath.Ext(name), ".md") {
+			note := strings.TrimSuffix(name, filepath.Ext(name))
+			v.notes[note] = append(v.notes[note], rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to index vault %s: %w", root, err)
+	}
+
+	return v, nil
+}
+
+// ResolveNote resolves a wiki-link target such as "Note" or "folder/Note" to
+// an absolute file path. sourceDir is the directory of the linking note.
+func (v *Vault) ResolveNote(target, sourceDir string) (string, bool) {
+	target = strings.TrimSuffix(target, ".md")
+	if strings.Contains(target, "/") {
+		return v.resolvePath(target+".md", sourceDir)
+	}
+	return v.pick(target, v.notes[strings.ToLower(target)], sourceDir)
+}
+
+// ResolveFile resolves an embed or link target that names a file with an
+// extension (for example an image) to an absolute file path
+func (v *Vault) ResolveFile(target, sourceDir string) (string, bool) {
+	if strings.Contains(target, "/") {
+		return v.resolvePath(target, sourceDir)
+	}
+	return v.pick(target, v.files[strings.ToLower(target)], sourceDir)
+}
+
+// resolvePath resolves a link containing a folder. Obsidian first treats it
+// as vault-relative, then relative to the linking note, and finally as a
+// path suffix ("folder/Note" matches "projects/folder/Note.md").
+func (v *Vault) resolvePath(target, sourceDir string) (string, bool) {
+	target = strings.TrimPrefix(target, "/")
+	if rel, ok := v.paths[strings.ToLower(target)]; ok {
+		return v.abs(rel), true
+	}
+
+	if relDir, err := filepath.Rel(v.Root, sourceDir); err == nil {
+		joined := filepath.ToSlash(filepath.Join(relDir, target))
+		if rel, ok := v.paths[strings.ToLower(joined)]; ok {
+			return v.abs(rel), true
+		}
+	}
+
+	suffix := "/" + strings.ToLower(target)
+	var candidates []string
+	for lower, rel := range v.paths {
+		if strings.HasSuffix(lower, suffix) {
+			candidates = append(candidates, rel)
+		}
+	}
+	return v.pick(target, candidates, sourceDir)
+}
+
+// pick chooses among notes or files sharing a name: the attachment folder
+// wins, then the linking note's folder, then the shortest path. Ambiguous
+// names produce a warning (once per target).
+func (v *Vault) pick(target string, candidates []string, sourceDir string) (string, bool) {
+	switch len(candidates) {
+	case 0:
+		return "", false
+	case 1:
+		return v.abs(candidates[0]), true
+	}
+
+	sorted := append([]string(nil), candidates...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if len(sorted[i]) != len(sorted[j]) {
+			return len(sorted[i]) < len(sorted[j])
+		}
+		return sorted[i] < sorted[j]
+	})
+
+	preferred := []string{v.attachmentDir(sourceDir), sourceDir}
+	for _, dir := range preferred {
+		if dir == "" {
+			continue
+		}
+		for _, rel := range sorted {
+			if filepath.Dir(v.abs(rel)) == dir {
+				return v.abs(rel), true
+			}
+		}
+	}
+
+	if !v.warned[strings.ToLower(target)] {
+		v.warned[strings.ToLower(target)] = true
+		fmt.Fprintf(os.Stderr, "Warning: ambiguous link [[%s]] matches %s; using %s\n",
+			target, strings.Join(sorted, ", "), sorted[0])
+	}
+	return v.abs(sorted[0]), true
+}
+
+// attachmentDir returns the absolute attachment folder for a note in
+// sourceDir, following Obsidian's attachmentFolderPath conventions:
+// "/" is the vault root, "./" the note's folder and "./sub" a subfolder of it.
+func (v *Vault) attachmentDir(sourceDir string) string {
+	folder := v.AttachmentFolder
+	switch {
+	case folder == "" || folder == "/":
+		return v.Root
+	case folder == "." || folder == "./":
+		return sourceDir
+	case strings.HasPrefix(folder, "./"):
+		return filepath.Join(sourceDir, filepath.FromSlash(folder[2:]))
+	default:
+		return filepath.Join(v.Root, filepath.FromSlash(folder))
+	}
+}
+
+// abs converts a vault-relative path to an absolute path
+func (v *Vault) abs(rel string) string {
+	return filepath.Join(v.Root, filepath.FromSlash(rel))
+}
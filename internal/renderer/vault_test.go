@@ -0,0 +1,89 @@
+package renderer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testVault creates a vault containing the given vault-relative files and
+// an .obsidian/app.json with the given attachment folder
+func testVault(t *testing.T, attachmentFolder string, files ...string) *Vault {
+	t.Helper()
+	root := t.TempDir()
+	files = append(files, ".obsidian/app.json")
+	for _, rel := range files {
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		content := ""
+		if rel == ".obsidian/app.json" {
+			content = `{"attachmentFolderPath": "` + attachmentFolder + `"}`
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	v, err := FindVault(filepath.Join(root, ".obsidian"))
+	if err != nil || v == nil {
+		t.Fatalf("FindVault: %v, %v", v, err)
+	}
+	return v
+}
+
+func TestVaultResolve(t *testing.T) {
+	tests := []struct {
+		name       string
+		attachment string
+		files      []string
+		target     string
+		file       bool   // ResolveFile rather than ResolveNote
+		source     string // Vault-relative directory of the linking note
+		want       string // Vault-relative result, "" if unresolved
+		ambiguous  bool
+	}{
+		{"unique note", "", []string{"notes/Note.md"}, "Note", false, "", "notes/Note.md", false},
+		{"case insensitive", "", []string{"notes/Note.md"}, "note", false, "", "notes/Note.md", false},
+		{"with extension", "", []string{"notes/Note.md"}, "Note.md", false, "", "notes/Note.md", false},
+		{"missing", "", []string{"notes/Note.md"}, "Other", false, "", "", false},
+		{"shortest path", "", []string{"b/c/Dup.md", "a/Dup.md"}, "Dup", false, "", "a/Dup.md", true},
+		{"linking note's folder", "", []string{"a/Dup.md", "b/c/Dup.md"}, "Dup", false, "b/c", "b/c/Dup.md", false},
+		{"vault path", "", []string{"a/Dup.md", "b/Dup.md"}, "b/Dup", false, "a", "b/Dup.md", false},
+		{"relative path", "", []string{"x/sub/Note.md", "sub/Note.md"}, "sub/Note", false, "x", "sub/Note.md", false},
+		{"path suffix", "", []string{"projects/folder/Note.md"}, "folder/Note", false, "", "projects/folder/Note.md", false},
+		{"attachment folder", "assets", []string{"notes/img.png", "assets/img.png"}, "img.png", true, "notes", "assets/img.png", false},
+		{"attachment subfolder", "./files", []string{"img.png", "notes/files/img.png"}, "img.png", true, "notes", "notes/files/img.png", false},
+		{"root attachment folder", "/", []string{"notes/img.png", "img.png"}, "img.png", true, "notes", "img.png", false},
+		{"hidden files ignored", "", []string{".trash/Note.md", "Note.md"}, "Note", false, "", "Note.md", false},
+	}
+	for _, tt := range tests {
+		v := testVault(t, tt.attachment, tt.files...)
+		sourceDir := filepath.Join(v.Root, filepath.FromSlash(tt.source))
+		var got string
+		var ok bool
+		if tt.file {
+			got, ok = v.ResolveFile(tt.target, sourceDir)
+		} else {
+			got, ok = v.ResolveNote(tt.target, sourceDir)
+		}
+
+		want := ""
+		if tt.want != "" {
+			want = filepath.Join(v.Root, filepath.FromSlash(tt.want))
+		}
+		if got != want || ok != (tt.want != "") {
+			t.Errorf("%s: resolved %q to %q, %v, want %q", tt.name, tt.target, got, ok, want)
+		}
+		if ambiguous := len(v.warned) > 0; ambiguous != tt.ambiguous {
+			t.Errorf("%s: ambiguity warning = %v, want %v", tt.name, ambiguous, tt.ambiguous)
+		}
+	}
+}
+
+func TestFindVaultOutsideVault(t *testing.T) {
+	v, err := FindVault(t.TempDir())
+	if err != nil || v != nil {
+		t.Errorf("FindVault outside a vault = %v, %v, want nil", v, err)
+	}
+}
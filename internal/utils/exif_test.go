@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"encoding/binary"
+	"image"
+	"image/color"
+	"testing"
+)
+
+// testJPEGHeader returns the start of a JPEG file whose APP1 segment holds a
+// TIFF block in the given byte order with one IFD0 entry for the orientation
+func testJPEGHeader(order binary.ByteOrder, orientation uint16) []byte {
+	tiff := make([]byte, 8+2+12+4)
+	if order == binary.LittleEndian {
+		copy(tiff, "II")
+	} else {
+		copy(tiff, "MM")
+	}
+	order.PutUint16(tiff[2:], 42)
+	order.PutUint32(tiff[4:], 8)
+	order.PutUint16(tiff[8:], 1)
+	order.PutUint16(tiff[10:], 0x0112) // Orientation
+	order.PutUint16(tiff[12:], 3)      // SHORT
+	order.PutUint32(tiff[14:], 1)
+	order.PutUint16(tiff[18:], orientation)
+
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	data := []byte{0xFF, 0xD8, 0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(data[4:], uint16(len(segment)+2))
+	data = append(data, segment...)
+	return append(data, 0xFF, 0xDA, 0, 2)
+}
+
+func TestJPEGOrientation(t *testing.T) {
+	// A JPEG whose first segment is not APP1
+	app0 := append([]byte{0xFF, 0xD8, 0xFF, 0xE0, 0, 4, 0, 0}, testJPEGHeader(binary.BigEndian, 8)[2:]...)
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{"little endian", testJPEGHeader(binary.LittleEndian, 6), 6},
+		{"big endian", testJPEGHeader(binary.BigEndian, 3), 3},
+		{"after APP0", app0, 8},
+		{"out of range", testJPEGHeader(binary.LittleEndian, 9), 1},
+		{"truncated", testJPEGHeader(binary.LittleEndian, 6)[:20], 1},
+		{"no Exif", []byte{0xFF, 0xD8, 0xFF, 0xDA, 0, 2}, 1},
+		{"PNG", testPNG, 1},
+		{"empty", nil, 1},
+	}
+	for _, tt := range tests {
+		if got := jpegOrientation(tt.data); got != tt.want {
+			t.Errorf("%s: jpegOrientation = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestApplyOrientation(t *testing.T) {
+	// A 2×3 image with only its top-left pixel set
+	src := image.NewRGBA(image.Rect(0, 0, 2, 3))
+	marked := color.RGBA{255, 0, 0, 255}
+	src.SetRGBA(0, 0, marked)
+
+	tests := []struct {
+		orientation int
+		size        image.Point
+		corner      image.Point // Where the top-left pixel ends up
+	}{
+		{1, image.Pt(2, 3), image.Pt(0, 0)},
+		{2, image.Pt(2, 3), image.Pt(1, 0)},
+		{3, image.Pt(2, 3), image.Pt(1, 2)},
+		{4, image.Pt(2, 3), image.Pt(0, 2)},
+		{5, image.Pt(3, 2), image.Pt(0, 0)},
+		{6, image.Pt(3, 2), image.Pt(2, 0)},
+		{7, image.Pt(3, 2), image.Pt(2, 1)},
+		{8, image.Pt(3, 2), image.Pt(0, 1)},
+	}
+	for _, tt := range tests {
+		got := applyOrientation(src, tt.orientation)
+		if size := got.Bounds().Size(); size != tt.size {
+			t.Errorf("orientation %d: size = %v, want %v", tt.orientation, size, tt.size)
+			continue
+		}
+		if c := color.RGBAModel.Convert(got.At(tt.corner.X, tt.corner.Y)); c != marked {
+			t.Errorf("orientation %d: pixel %v = %v, want the top-left pixel", tt.orientation, tt.corner, c)
+		}
+	}
+}
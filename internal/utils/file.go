@@ -49,3 +49,8 @@ func IsMarkdownFile(path string) bool {
 	ext := filepath.Ext(path)
 	return ext == ".md" || ext == ".markdown" || ext == ".mdown" || ext == ".mkd"
 }
+
+// IsCanvasFile checks if the file is an Obsidian canvas
+func IsCanvasFile(path string) bool {
+	return filepath.Ext(path) == ".canvas"
+}
@@ -0,0 +1,120 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"time"
+)
+
+// defaultGIFDelay is used for frames without a delay, as browsers do
+const defaultGIFDelay = 100 * time.Millisecond
+
+// GIFFrameCount returns the number of frames in a GIF image, or 0 if the
+// data is not a GIF. It walks the GIF's blocks without decoding any frame.
+func GIFFrameCount(imageData []byte) int {
+	if !bytes.HasPrefix(imageData, []byte("GIF87a")) && !bytes.HasPrefix(imageData, []byte("GIF89a")) {
+		return 0
+	}
+	// Header and logical screen descriptor, then the global color table
+	pos := 13
+	if len(imageData) < pos {
+		return 0
+	}
+	if flags := imageData[10]; flags&0x80 != 0 {
+		pos += 3 << (flags&0x07 + 1)
+	}
+
+	frames := 0
+	for pos < len(imageData) {
+		switch imageData[pos] {
+		case 0x21: // Extension: label, then data sub-blocks
+			pos += 2
+		case 0x2C: // Image descriptor, local color table, LZW code size, then data sub-blocks
+			frames++
+			if pos+10 > len(imageData) {
+				return frames
+			}
+			if flags := imageData[pos+9]; flags&0x80 != 0 {
+				pos += 3 << (flags&0x07 + 1)
+			}
+			pos += 11
+		default: // Trailer (0x3B) or corrupt data
+			return frames
+		}
+		// Skip the data sub-blocks up to the zero-length terminator
+		for pos < len(imageData) && imageData[pos] != 0 {
+			pos += int(imageData[pos]) + 1
+		}
+		pos++
+	}
+	return frames
+}
+
+// GIFFrames decodes the frames of an animated GIF as full images, each drawn
+// over the previous ones according to the frames' disposal methods, along
+// with the time each frame is shown
+func GIFFrames(imageData []byte) ([]image.Image, []time.Duration, error) {
+	g, err := gif.DecodeAll(bytes.NewReader(imageData))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decode GIF: %w", err)
+	}
+
+	bounds := image.Rect(0, 0, g.Config.Width, g.Config.Height)
+	canvas := image.NewRGBA(bounds)
+	frames := make([]image.Image, len(g.Image))
+	delays := make([]time.Duration, len(g.Image))
+	for i, frame := range g.Image {
+		var previous *image.RGBA
+		if i < len(g.Disposal) && g.Disposal[i] == gif.DisposalPrevious {
+			previous = image.NewRGBA(bounds)
+			draw.Draw(previous, bounds, canvas, image.Point{}, draw.Src)
+		}
+
+		draw.Draw(canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+		snapshot := image.NewRGBA(bounds)
+		draw.Draw(snapshot, bounds, canvas, image.Point{}, draw.Src)
+		frames[i] = snapshot
+
+		delays[i] = defaultGIFDelay
+		if i < len(g.Delay) && g.Delay[i] > 0 {
+			delays[i] = time.Duration(g.Delay[i]) * 10 * time.Millisecond
+		}
+
+		// Prepare the canvas for the next frame
+		if i < len(g.Disposal) {
+			switch g.Disposal[i] {
+			case gif.DisposalBackground:
+				draw.Draw(canvas, frame.Bounds(), image.Transparent, image.Point{}, draw.Src)
+			case gif.DisposalPrevious:
+				canvas = previous
+			}
+		}
+	}
+	return frames, delays, nil
+}
+
+// GIFFirstFrame returns the first frame of a GIF as a PNG image the size of
+// the whole animation
+func GIFFirstFrame(imageData []byte) ([]byte, error) {
+	config, err := gif.DecodeConfig(bytes.NewReader(imageData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode GIF: %w", err)
+	}
+	frame, err := gif.Decode(bytes.NewReader(imageData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode GIF: %w", err)
+	}
+
+	canvas := image.NewRGBA(image.Rect(0, 0, config.Width, config.Height))
+	draw.Draw(canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, canvas); err != nil {
+		return nil, fmt.Errorf("failed to encode image: %w", err)
+	}
+	return buf.Bytes(), nil
+}
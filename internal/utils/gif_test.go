@@ -0,0 +1,114 @@
+package utils
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"testing"
+	"time"
+)
+
+var gifPalette = color.Palette{color.Transparent, color.Black, color.White}
+
+// testGIF encodes a 4×4 animation. Frame i fills its rect with black (or
+// white for odd frames) and is disposed with disposal[i].
+func testGIF(t *testing.T, rects []image.Rectangle, disposal []byte, delays []int) []byte {
+	t.Helper()
+	g := &gif.GIF{Config: image.Config{Width: 4, Height: 4, ColorModel: gifPalette}}
+	for i, rect := range rects {
+		frame := image.NewPaletted(rect, gifPalette)
+		for y := rect.Min.Y; y < rect.Max.Y; y++ {
+			for x := rect.Min.X; x < rect.Max.X; x++ {
+				frame.SetColorIndex(x, y, uint8(1+i%2))
+			}
+		}
+		g.Image = append(g.Image, frame)
+		g.Delay = append(g.Delay, delays[i])
+		g.Disposal = append(g.Disposal, disposal[i])
+	}
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, g); err != nil {
+		t.Fatalf("EncodeAll: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestGIFFrameCount(t *testing.T) {
+	full := image.Rect(0, 0, 4, 4)
+	three := testGIF(t, []image.Rectangle{full, full, full}, []byte{0, 0, 0}, []int{1, 1, 1})
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{"single frame", testGIF(t, []image.Rectangle{full}, []byte{0}, []int{0}), 1},
+		{"three frames", three, 3},
+		{"truncated", three[:len(three)/2], 1},
+		{"PNG", testPNG, 0},
+		{"empty", nil, 0},
+		{"header only", []byte("GIF89a"), 0},
+	}
+	for _, tt := range tests {
+		if got := GIFFrameCount(tt.data); got != tt.want {
+			t.Errorf("%s: GIFFrameCount = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGIFFrames(t *testing.T) {
+	full := image.Rect(0, 0, 4, 4)
+	corner := image.Rect(0, 0, 2, 2)
+	tests := []struct {
+		name     string
+		disposal byte
+		want     color.Color // Pixel (3, 3) of the second frame
+	}{
+		{"keep", gif.DisposalNone, color.RGBA{0, 0, 0, 255}},
+		{"background", gif.DisposalBackground, color.RGBA{}},
+	}
+	for _, tt := range tests {
+		data := testGIF(t, []image.Rectangle{full, corner}, []byte{tt.disposal, 0}, []int{5, 0})
+		frames, delays, err := GIFFrames(data)
+		if err != nil {
+			t.Fatalf("%s: GIFFrames: %v", tt.name, err)
+		}
+		if len(frames) != 2 {
+			t.Fatalf("%s: got %d frames, want 2", tt.name, len(frames))
+		}
+		if b := frames[1].Bounds(); b != full {
+			t.Errorf("%s: frame bounds = %v, want %v", tt.name, b, full)
+		}
+		if got := color.RGBAModel.Convert(frames[1].At(3, 3)); got != tt.want {
+			t.Errorf("%s: pixel outside the second frame = %v, want %v", tt.name, got, tt.want)
+		}
+		if got := color.RGBAModel.Convert(frames[1].At(0, 0)); got != (color.RGBA{255, 255, 255, 255}) {
+			t.Errorf("%s: pixel inside the second frame = %v, want white", tt.name, got)
+		}
+		if want := []time.Duration{50 * time.Millisecond, defaultGIFDelay}; delays[0] != want[0] || delays[1] != want[1] {
+			t.Errorf("%s: delays = %v, want %v", tt.name, delays, want)
+		}
+	}
+}
+
+func TestGIFFirstFrame(t *testing.T) {
+	data := testGIF(t, []image.Rectangle{image.Rect(1, 1, 3, 3), image.Rect(0, 0, 4, 4)}, []byte{0, 0}, []int{1, 1})
+	first, err := GIFFirstFrame(data)
+	if err != nil {
+		t.Fatalf("GIFFirstFrame: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(first))
+	if err != nil {
+		t.Fatalf("first frame is not a PNG: %v", err)
+	}
+	if b := img.Bounds(); b != image.Rect(0, 0, 4, 4) {
+		t.Errorf("first frame bounds = %v, want the whole animation", b)
+	}
+	if _, _, _, a := img.At(0, 0).RGBA(); a != 0 {
+		t.Errorf("pixel outside the first frame is not transparent")
+	}
+	if r, _, _, a := img.At(1, 1).RGBA(); r != 0 || a == 0 {
+		t.Errorf("pixel inside the first frame is not black")
+	}
+}
@@ -0,0 +1,209 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Default limits for remote image downloads
+const (
+	DefaultRemoteImageMaxBytes = 5 << 20 // 5 MiB
+	DefaultRemoteImageTimeout  = 10 * time.Second
+)
+
+// ErrOffline is returned when a remote image is requested in offline mode
+// and no cached copy is available.
+var ErrOffline = errors.New("offline mode: remote image not in cache")
+
+// Content types accepted from remote image servers
+var remoteImageContentTypes = map[string]bool{
+	"image/png":     true,
+	"image/jpeg":    true,
+	"image/gif":     true,
+	"image/webp":    true,
+	"image/svg+xml": true,
+}
+
+// remoteImageMeta is the metadata stored next to each cached image
+type remoteImageMeta struct {
+	URL         string `json:"url"`
+	ETag        string `json:"etag,omitempty"`
+	ContentType string `json:"content_type"`
+}
+
+// RemoteImageFetcher downloads remote images and keeps an on-disk cache
+// keyed by URL. Cached entries are revalidated with their ETag.
+type RemoteImageFetcher struct {
+	cacheDir string
+	maxBytes int64
+	offline  bool
+	client   *http.Client
+}
+
+// NewRemoteImageFetcher creates a fetcher that caches images in cacheDir
+func NewRemoteImageFetcher(cacheDir string) *RemoteImageFetcher {
+	return &RemoteImageFetcher{
+		cacheDir: cacheDir,
+		maxBytes: DefaultRemoteImageMaxBytes,
+		client:   &http.Client{Timeout: DefaultRemoteImageTimeout},
+	}
+}
+
+// DefaultImageCacheDir returns the directory used to cache remote images
+func DefaultImageCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "mdviewer", "images")
+}
+
+// SetTimeout sets the timeout for a single download
+func (f *RemoteImageFetcher) SetTimeout(timeout time.Duration) {
+	f.client.Timeout = timeout
+}
+
+// SetMaxBytes sets the maximum accepted image size in bytes
+func (f *RemoteImageFetcher) SetMaxBytes(maxBytes int64) {
+	f.maxBytes = maxBytes
+}
+
+// SetOffline prevents any network access; only cached images are returned
+func (f *RemoteImageFetcher) SetOffline(offline bool) {
+	f.offline = offline
+}
+
+// ParseByteSize parses a size such as "5MB", "512KB" or "1048576" into bytes.
+// KB, MB and GB are binary multiples (1 KB = 1024 bytes).
+func ParseByteSize(size string) (int64, error) {
+	s := strings.TrimSpace(strings.ToUpper(size))
+	units := []struct {
+		suffix string
+		bytes  int64
+	}{
+		{"KIB", 1 << 10}, {"MIB", 1 << 20}, {"GIB", 1 << 30},
+		{"KB", 1 << 10}, {"MB", 1 << 20}, {"GB", 1 << 30},
+		{"K", 1 << 10}, {"M", 1 << 20}, {"G", 1 << 30},
+		{"B", 1},
+	}
+	factor := int64(1)
+	for _, unit := range units {
+		if strings.HasSuffix(s, unit.suffix) {
+			s = strings.TrimSpace(strings.TrimSuffix(s, unit.suffix))
+			factor = unit.bytes
+			break
+		}
+	}
+
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil || value <= 0 {
+		return 0, fmt.Errorf("invalid size %q (use a value such as 5MB or 512KB)", size)
+	}
+	return int64(value * float64(factor)), nil
+}
+
+// Fetch returns the image data for url, using the cache when possible
+func (f *RemoteImageFetcher) Fetch(url string) ([]byte, error) {
+	key := cacheKey(url)
+	meta, cached, cacheErr := f.loadCached(key)
+
+	if f.offline {
+		if cacheErr != nil {
+			return nil, ErrOffline
+		}
+		return cached, nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid image URL %s: %w", url, err)
+	}
+	if cacheErr == nil && meta.ETag != "" {
+		req.Header.Set("If-None-Match", meta.ETag)
+	}
+	req.Header.Set("Accept", "image/png, image/jpeg, image/gif, image/webp, image/svg+xml")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && cacheErr == nil {
+		return cached, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %s: %s", url, resp.Status)
+	}
+
+	contentType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || !remoteImageContentTypes[contentType] {
+		return nil, fmt.Errorf("unsupported content type %q for %s", resp.Header.Get("Content-Type"), url)
+	}
+
+	if resp.ContentLength > f.maxBytes {
+		return nil, fmt.Errorf("image %s exceeds size limit (%d > %d bytes)", url, resp.ContentLength, f.maxBytes)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, f.maxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", url, err)
+	}
+	if int64(len(data)) > f.maxBytes {
+		return nil, fmt.Errorf("image %s exceeds size limit of %d bytes", url, f.maxBytes)
+	}
+
+	// A failed cache write is not fatal; the image is still displayed.
+	_ = f.storeCached(key, remoteImageMeta{
+		URL:         url,
+		ETag:        resp.Header.Get("ETag"),
+		ContentType: contentType,
+	}, data)
+
+	return data, nil
+}
+
+// loadCached reads a cached image and its metadata
+func (f *RemoteImageFetcher) loadCached(key string) (remoteImageMeta, []byte, error) {
+	var meta remoteImageMeta
+	raw, err := os.ReadFile(filepath.Join(f.cacheDir, key+".json"))
+	if err != nil {
+		return meta, nil, err
+	}
+	if err := json.Unmarshal(raw, &meta); err != nil {
+		return meta, nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(f.cacheDir, key+".img"))
+	if err != nil {
+		return meta, nil, err
+	}
+	return meta, data, nil
+}
+
+// storeCached writes an image and its metadata to the cache
+func (f *RemoteImageFetcher) storeCached(key string, meta remoteImageMeta, data []byte) error {
+	raw, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	if err := WriteFile(filepath.Join(f.cacheDir, key+".img"), data); err != nil {
+		return err
+	}
+	return WriteFile(filepath.Join(f.cacheDir, key+".json"), raw)
+}
+
+// cacheKey derives a file-system safe cache key from a URL
+func cacheKey(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,209 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var testPNG = []byte("\x89PNG\r\n\x1a\nnot really a png")
+
+// serverStats counts the requests an image server received
+type serverStats struct {
+	requests    atomic.Int32
+	notModified atomic.Int32
+}
+
+// imageServer serves body with the given content type and ETag, answering
+// matching If-None-Match requests with 304
+func imageServer(t *testing.T, contentType, etag string, body []byte) (*httptest.Server, *serverStats) {
+	t.Helper()
+	stats := &serverStats{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stats.requests.Add(1)
+		if etag != "" {
+			w.Header().Set("ETag", etag)
+			if r.Header.Get("If-None-Match") == etag {
+				stats.notModified.Add(1)
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	return server, stats
+}
+
+func TestFetchCacheHit(t *testing.T) {
+	server, stats := imageServer(t, "image/png", "", testPNG)
+	f := NewRemoteImageFetcher(t.TempDir())
+
+	if _, err := f.Fetch(server.URL + "/a.png"); err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	server.Close()
+
+	// The server is gone; the cached copy is served offline
+	f.SetOffline(true)
+	data, err := f.Fetch(server.URL + "/a.png")
+	if err != nil {
+		t.Fatalf("Fetch from cache: %v", err)
+	}
+	if !bytes.Equal(data, testPNG) {
+		t.Errorf("cached data = %q, want %q", data, testPNG)
+	}
+	if n := stats.requests.Load(); n != 1 {
+		t.Errorf("server got %d requests, want 1", n)
+	}
+}
+
+func TestFetchETagRevalidation(t *testing.T) {
+	server, stats := imageServer(t, "image/png", `"v1"`, testPNG)
+	f := NewRemoteImageFetcher(t.TempDir())
+
+	for i := 0; i < 2; i++ {
+		data, err := f.Fetch(server.URL + "/a.png")
+		if err != nil {
+			t.Fatalf("Fetch %d: %v", i+1, err)
+		}
+		if !bytes.Equal(data, testPNG) {
+			t.Errorf("Fetch %d = %q, want %q", i+1, data, testPNG)
+		}
+	}
+	if n := stats.requests.Load(); n != 2 {
+		t.Errorf("server got %d requests, want 2", n)
+	}
+	if n := stats.notModified.Load(); n != 1 {
+		t.Errorf("server answered %d requests with 304, want 1", n)
+	}
+}
+
+func TestFetchSizeLimit(t *testing.T) {
+	server, _ := imageServer(t, "image/png", "", bytes.Repeat([]byte("x"), 2048))
+	f := NewRemoteImageFetcher(t.TempDir())
+	f.SetMaxBytes(1024)
+
+	if _, err := f.Fetch(server.URL + "/big.png"); err == nil {
+		t.Fatal("Fetch of an oversized image succeeded")
+	}
+
+	// Without a Content-Length the body is cut off at the limit
+	streamed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		for i := 0; i < 4; i++ {
+			w.Write(bytes.Repeat([]byte("x"), 512))
+			w.(http.Flusher).Flush()
+		}
+	}))
+	t.Cleanup(streamed.Close)
+	if _, err := f.Fetch(streamed.URL + "/big.png"); err == nil {
+		t.Fatal("Fetch of an oversized streamed image succeeded")
+	}
+
+	// Nothing is cached for a rejected image
+	f.SetOffline(true)
+	if _, err := f.Fetch(server.URL + "/big.png"); !errors.Is(err, ErrOffline) {
+		t.Errorf("offline Fetch error = %v, want ErrOffline", err)
+	}
+}
+
+func TestFetchContentType(t *testing.T) {
+	tests := []struct {
+		contentType string
+		ok          bool
+	}{
+		{"image/png", true},
+		{"image/svg+xml; charset=utf-8", true},
+		{"text/html; charset=utf-8", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		server, _ := imageServer(t, tt.contentType, "", testPNG)
+		f := NewRemoteImageFetcher(t.TempDir())
+		if _, err := f.Fetch(server.URL + "/image"); (err == nil) != tt.ok {
+			t.Errorf("Fetch with Content-Type %q: err = %v, want ok = %v", tt.contentType, err, tt.ok)
+		}
+	}
+}
+
+func TestFetchTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(2 * time.Second):
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	f := NewRemoteImageFetcher(t.TempDir())
+	f.SetTimeout(50 * time.Millisecond)
+	start := time.Now()
+	if _, err := f.Fetch(server.URL + "/slow.png"); err == nil {
+		t.Fatal("Fetch from a stalled server succeeded")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Fetch took %v, want it to stop at the timeout", elapsed)
+	}
+}
+
+func TestFetchOffline(t *testing.T) {
+	server, stats := imageServer(t, "image/png", "", testPNG)
+	f := NewRemoteImageFetcher(t.TempDir())
+	f.SetOffline(true)
+
+	if _, err := f.Fetch(server.URL + "/a.png"); !errors.Is(err, ErrOffline) {
+		t.Errorf("Fetch error = %v, want ErrOffline", err)
+	}
+	if n := stats.requests.Load(); n != 0 {
+		t.Errorf("server got %d requests in offline mode, want 0", n)
+	}
+}
+
+func TestIsSVG(t *testing.T) {
+	tests := []struct {
+		data string
+		want bool
+	}{
+		{`<svg xmlns="http://www.w3.org/2000/svg"></svg>`, true},
+		{"\xef\xbb\xbf<?xml version=\"1.0\"?>\n<svg></svg>", true},
+		{string(testPNG), false},
+		{"<html><body>not an image</body></html>", false},
+	}
+	for _, tt := range tests {
+		if got := IsSVG([]byte(tt.data)); got != tt.want {
+			t.Errorf("IsSVG(%q) = %v, want %v", tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		size string
+		want int64
+		ok   bool
+	}{
+		{"1048576", 1 << 20, true},
+		{"512KB", 512 << 10, true},
+		{"5MB", 5 << 20, true},
+		{"5 mib", 5 << 20, true},
+		{"1.5M", 3 << 19, true},
+		{"1GB", 1 << 30, true},
+		{"100B", 100, true},
+		{"0", 0, false},
+		{"-1MB", 0, false},
+		{"MB", 0, false},
+		{"5TB", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseByteSize(tt.size)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("ParseByteSize(%q) = %d, %v, want %d, ok = %v", tt.size, got, err, tt.want, tt.ok)
+		}
+	}
+}
@@ -8,13 +8,115 @@ import (
 	_ "image/jpeg"
 	"image/png"
 
+	_ "golang.org/x/image/bmp"
 	"golang.org/x/image/draw"
+	_ "golang.org/x/image/tiff"
+	_ "golang.org/x/image/webp"
 )
 
+// DecodeImage decodes PNG, JPEG, GIF, WebP, BMP and TIFF data. JPEG images
+// are rotated according to their EXIF orientation so phone photos display
+// upright.
+func DecodeImage(imageData []byte) (image.Image, string, error) {
+	img, format, err := image.Decode(bytes.NewReader(imageData))
+	if err != nil {
+		return nil, "", err
+	}
+	if format == "jpeg" {
+		img = applyOrientation(img, jpegOrientation(imageData))
+	}
+	return img, format, nil
+}
+
+// PrepareImageForDisplay converts image data into a form the terminal image
+// protocol can show. PNG, GIF and upright JPEG data pass through unchanged for
+// iTerm2-style protocols; everything else (WebP, BMP, TIFF, rotated JPEGs, and
+// any non-PNG image for Kitty) is re-encoded as PNG.
+func PrepareImageForDisplay(imageData []byte, protocol TerminalImageProtocol) ([]byte, error) {
+	_, format, err := image.DecodeConfig(bytes.NewReader(imageData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	switch format {
+	case "png":
+		return imageData, nil
+	case "gif":
+		if protocol != ProtocolKitty {
+			return imageData, nil
+		}
+	case "jpeg":
+		if protocol != ProtocolKitty && jpegOrientation(imageData) == 1 {
+			return imageData, nil
+		}
+	}
+
+	img, _, err := DecodeImage(imageData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode image: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// ResampleFilter selects the interpolation used when resizing images
+type ResampleFilter string
+
+const (
+	ResampleBilinear   ResampleFilter = "bilinear"    // Smooth, fast (default)
+	ResampleCatmullRom ResampleFilter = "catmull-rom" // Sharpest for photos, slower
+	ResampleNearest    ResampleFilter = "nearest"     // Blocky; keeps pixel art and text crisp
+)
+
+// ParseResampleFilter converts a user supplied filter name to a ResampleFilter
+func ParseResampleFilter(name string) (ResampleFilter, error) {
+	switch name {
+	case "", "bilinear":
+		return ResampleBilinear, nil
+	case "catmull-rom", "catmullrom", "bicubic":
+		return ResampleCatmullRom, nil
+	case "nearest", "nearest-neighbor":
+		return ResampleNearest, nil
+	default:
+		return "", fmt.Errorf("unknown resampling filter %q (use bilinear, catmull-rom or nearest)", name)
+	}
+}
+
+// scaler returns the x/image scaler for the filter
+func (f ResampleFilter) scaler() draw.Scaler {
+	switch f {
+	case ResampleCatmullRom:
+		return draw.CatmullRom
+	case ResampleNearest:
+		return draw.NearestNeighbor
+	default:
+		return draw.BiLinear
+	}
+}
+
+// ResizeOptions describes the target size of a resized image. When both
+// Width and Height are set the image is scaled to fit inside that box; when
+// only one is set the other follows the aspect ratio.
+type ResizeOptions struct {
+	Width  int
+	Height int
+	Filter ResampleFilter
+}
+
 // ResizeImage resizes an image to the specified width, maintaining aspect ratio
 func ResizeImage(imageData []byte, targetWidth int) ([]byte, error) {
+	return ResizeImageWithOptions(imageData, ResizeOptions{Width: targetWidth})
+}
+
+// ResizeImageWithOptions resizes an image to fit the given width and/or
+// height, maintaining aspect ratio
+func ResizeImageWithOptions(imageData []byte, opts ResizeOptions) ([]byte, error) {
 	// Decode image
-	img, format, err := image.Decode(bytes.NewReader(imageData))
+	img, format, err := DecodeImage(imageData)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode image: %w", err)
 	}
@@ -24,20 +126,18 @@ func ResizeImage(imageData []byte, targetWidth int) ([]byte, error) {
 	origWidth := bounds.Dx()
 	origHeight := bounds.Dy()
 
-	// If already at target width, return original
-	if origWidth == targetWidth {
-		return imageData, nil
+	targetWidth, targetHeight := FitSize(origWidth, origHeight, opts.Width, opts.Height)
+	if targetWidth == 0 || targetHeight == 0 {
+		return nil, fmt.Errorf("invalid target size %dx%d", opts.Width, opts.Height)
 	}
 
-	// Calculate new height maintaining aspect ratio
-	aspectRatio := float64(origHeight) / float64(origWidth)
-	targetHeight := int(float64(targetWidth) * aspectRatio)
-
-	// Create new image with target dimensions
-	dst := image.NewRGBA(image.Rect(0, 0, targetWidth, targetHeight))
+	// If already at target size, return original (unless it had to be
+	// rotated upright)
+	if targetWidth == origWidth && targetHeight == origHeight && (format != "jpeg" || jpegOrientation(imageData) == 1) {
+		return imageData, nil
+	}
 
-	// Resize using bilinear interpolation
-	draw.BiLinear.Scale(dst, dst.Bounds(), img, bounds, draw.Over, nil)
+	dst := scaleImage(img, targetWidth, targetHeight, opts.Filter)
 
 	// Encode to PNG (use PNG for lossless quality)
 	var buf bytes.Buffer
@@ -53,3 +153,45 @@ func ResizeImage(imageData []byte, targetWidth int) ([]byte, error) {
 
 	return buf.Bytes(), nil
 }
+
+// ScaleImage resizes a decoded image to fit the given width and/or height,
+// maintaining aspect ratio
+func ScaleImage(img image.Image, opts ResizeOptions) image.Image {
+	bounds := img.Bounds()
+	targetWidth, targetHeight := FitSize(bounds.Dx(), bounds.Dy(), opts.Width, opts.Height)
+	if targetWidth == 0 || targetHeight == 0 || (targetWidth == bounds.Dx() && targetHeight == bounds.Dy()) {
+		return img
+	}
+	return scaleImage(img, targetWidth, targetHeight, opts.Filter)
+}
+
+// scaleImage resizes img to exactly width×height with the filter
+func scaleImage(img image.Image, width, height int, filter ResampleFilter) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	filter.scaler().Scale(dst, dst.Bounds(), img, img.Bounds(), draw.Over, nil)
+	return dst
+}
+
+// FitSize computes the size of a w×h image scaled to the requested box,
+// maintaining aspect ratio. A zero maxWidth or maxHeight leaves that
+// dimension unconstrained; if both are zero the original size is returned.
+func FitSize(w, h, maxWidth, maxHeight int) (int, int) {
+	if w <= 0 || h <= 0 {
+		return 0, 0
+	}
+
+	switch {
+	case maxWidth > 0 && maxHeight > 0:
+		// Scale by whichever side is the tighter constraint
+		if w*maxHeight > h*maxWidth {
+			return maxWidth, max(1, h*maxWidth/w)
+		}
+		return max(1, w*maxHeight/h), maxHeight
+	case maxWidth > 0:
+		return maxWidth, max(1, h*maxWidth/w)
+	case maxHeight > 0:
+		return max(1, w*maxHeight/h), maxHeight
+	default:
+		return w, h
+	}
+}
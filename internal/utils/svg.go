@@ -0,0 +1,115 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// IsSVG reports whether image data is an SVG document, for images whose
+// name does not tell (such as remote badges)
+func IsSVG(imageData []byte) bool {
+	head := imageData[:min(len(imageData), 1024)]
+	head = bytes.TrimSpace(bytes.TrimPrefix(head, []byte("\xef\xbb\xbf")))
+	return bytes.HasPrefix(head, []byte("<")) && bytes.Contains(head, []byte("<svg"))
+}
+
+// SVGDimensions extracts width and height from SVG content
+func SVGDimensions(svg string) (width, height int) {
+	// Default dimensions
+	width, height = 800, 600
+
+	// Try to extract from viewBox
+	viewBoxRegex := regexp.MustCompile(`viewBox="[^"]*\s+([0-9.]+)\s+([0-9.]+)"`)
+	if matches := viewBoxRegex.FindStringSubmatch(svg); len(matches) == 3 {
+		fmt.Sscanf(matches[1], "%d", &width)
+		fmt.Sscanf(matches[2], "%d", &height)
+		return
+	}
+
+	// Try to extract from width/height attributes
+	widthRegex := regexp.MustCompile(`width="([0-9.]+)"`)
+	heightRegex := regexp.MustCompile(`height="([0-9.]+)"`)
+
+	if matches := widthRegex.FindStringSubmatch(svg); len(matches) == 2 {
+		fmt.Sscanf(matches[1], "%d", &width)
+	}
+	if matches := heightRegex.FindStringSubmatch(svg); len(matches) == 2 {
+		fmt.Sscanf(matches[1], "%d", &height)
+	}
+
+	return
+}
+
+// RasterizeSVG renders SVG data to PNG bytes in a headless Chrome started
+// for the call. The image is scaled to fit inside width×height while keeping
+// the SVG's aspect ratio; a zero width or height leaves that side
+// unconstrained, and if both are zero the SVG's intrinsic size is used.
+func RasterizeSVG(data []byte, width, height int) ([]byte, error) {
+	ctx, cancel := chromedp.NewContext(context.Background())
+	defer cancel()
+	return rasterizeSVG(ctx, string(data), width, height)
+}
+
+// rasterizeSVG renders SVG content to PNG bytes in the headless Chrome of
+// ctx, a chromedp context
+func rasterizeSVG(ctx context.Context, svg string, width, height int) ([]byte, error) {
+	svgWidth, svgHeight := SVGDimensions(svg)
+	if svgWidth <= 0 || svgHeight <= 0 {
+		svgWidth, svgHeight = 800, 600
+	}
+	width, height = FitSize(svgWidth, svgHeight, width, height)
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	// Load the SVG through an <img> data URL so scripts inside it never run
+	dataURL := "data:image/svg+xml;base64," + base64.StdEncoding.EncodeToString([]byte(svg))
+	srcJSON, err := json.Marshal(dataURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal SVG data: %w", err)
+	}
+
+	var loaded map[string]interface{}
+	var pngBytes []byte
+	err = chromedp.Run(ctx,
+		chromedp.Navigate("about:blank"),
+		// Set viewport size
+		chromedp.EmulateViewport(int64(width), int64(height)),
+		// Inject the image and record when it has loaded
+		chromedp.Evaluate(fmt.Sprintf(`
+			window.svgLoaded = null;
+			document.body.style.margin = '0';
+			const img = new Image();
+			img.style.display = 'block';
+			img.style.width = '%dpx';
+			img.style.height = '%dpx';
+			img.onload = () => { window.svgLoaded = { success: true }; };
+			img.onerror = () => { window.svgLoaded = { success: false }; };
+			img.src = %s;
+			document.body.appendChild(img);
+		`, width, height, srcJSON), nil),
+		// Wait for the image to load
+		chromedp.Poll(`window.svgLoaded`, &loaded, chromedp.WithPollingTimeout(20*time.Second)),
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			if ok, _ := loaded["success"].(bool); !ok {
+				return fmt.Errorf("browser could not load SVG")
+			}
+			return nil
+		}),
+		// Take screenshot
+		chromedp.FullScreenshot(&pngBytes, 100),
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to rasterize SVG: %w", err)
+	}
+
+	return pngBytes, nil
+}
@@ -1,9 +1,14 @@
 package utils
 
 import (
+	"bytes"
 	"encoding/base64"
 	"fmt"
+	"image"
+	"image/png"
 	"os"
+	"sync/atomic"
+	"time"
 )
 
 // TerminalImageProtocol represents different terminal inline image protocols
@@ -52,6 +57,16 @@ func DetectImageProtocol() TerminalImageProtocol {
 
 // DisplayInlineImage displays an image inline using the terminal's supported protocol
 func DisplayInlineImage(imageData []byte, protocol TerminalImageProtocol) error {
+	if protocol == ProtocolNone {
+		return fmt.Errorf("inline images not supported in this terminal")
+	}
+
+	// Convert formats the protocol cannot show (and rotated JPEGs) to PNG
+	imageData, err := PrepareImageForDisplay(imageData, protocol)
+	if err != nil {
+		return err
+	}
+
 	switch protocol {
 	case ProtocolITerm2:
 		return displayITerm2Image(imageData)
@@ -76,17 +91,67 @@ func displayITerm2Image(imageData []byte) error {
 }
 
 // displayKittyImage displays an image using Kitty's graphics protocol
-// Simplified version - just transmit and display
 func displayKittyImage(imageData []byte) error {
-	encoded := base64.StdEncoding.EncodeToString(imageData)
-	
-	// Kitty protocol: ESC _G<control data>;base64_data ESC \
 	// a=T means transmit and display, f=100 means PNG format
-	fmt.Printf("\033_Ga=T,f=100;%s\033\\\n", encoded)
-	
+	writeKittyCommand("a=T,f=100", imageData)
+	fmt.Println()
+	return nil
+}
+
+// kittyImageID numbers the animations sent to Kitty; it starts at a
+// per-process offset so concurrent viewers do not reuse each other's IDs
+var kittyImageID atomic.Uint32
+
+// DisplayKittyAnimation plays frames in place with Kitty's animation
+// protocol: the first frame is displayed, the others are added to it with
+// their delays, and the animation loops until the image scrolls away.
+func DisplayKittyAnimation(frames []image.Image, delays []time.Duration) error {
+	if len(frames) == 0 {
+		return fmt.Errorf("animation has no frames")
+	}
+	kittyImageID.CompareAndSwap(0, uint32(os.Getpid())<<8)
+	id := kittyImageID.Add(1)
+
+	encoded := make([][]byte, len(frames))
+	for i, frame := range frames {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, frame); err != nil {
+			return fmt.Errorf("failed to encode frame: %w", err)
+		}
+		encoded[i] = buf.Bytes()
+	}
+
+	// q=2 keeps Kitty from answering on stdin
+	writeKittyCommand(fmt.Sprintf("a=T,f=100,i=%d,q=2", id), encoded[0])
+	fmt.Printf("\033_Ga=a,i=%d,r=1,z=%d,q=2\033\\", id, delays[0].Milliseconds())
+	for i := 1; i < len(encoded); i++ {
+		writeKittyCommand(fmt.Sprintf("a=f,f=100,i=%d,z=%d,q=2", id, delays[i].Milliseconds()), encoded[i])
+	}
+	// s=3 runs the animation, v=1 loops it forever
+	fmt.Printf("\033_Ga=a,i=%d,s=3,v=1,q=2\033\\\n", id)
 	return nil
 }
 
+// writeKittyCommand sends a Kitty graphics command with its payload base64
+// encoded, split into the 4096 byte chunks the protocol requires
+func writeKittyCommand(control string, payload []byte) {
+	const chunkSize = 4096
+	encoded := base64.StdEncoding.EncodeToString(payload)
+	for first := true; first || encoded != ""; first = false {
+		chunk := encoded[:min(chunkSize, len(encoded))]
+		encoded = encoded[len(chunk):]
+		more := 0
+		if encoded != "" {
+			more = 1
+		}
+		if first {
+			fmt.Printf("\033_G%s,m=%d;%s\033\\", control, more, chunk)
+		} else {
+			fmt.Printf("\033_Gm=%d,q=2;%s\033\\", more, chunk)
+		}
+	}
+}
+
 // displaySixelImage displays an image using Sixel protocol
 // Note: This is a simplified implementation that outputs PNG as base64
 // A full implementation would convert to actual Sixel format
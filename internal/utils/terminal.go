@@ -2,6 +2,8 @@ package utils
 
 import (
 	"os"
+	"strconv"
+	"strings"
 
 	"golang.org/x/term"
 )
@@ -25,3 +27,64 @@ func IsTerminal() bool {
 func IsTTY(fd int) bool {
 	return term.IsTerminal(fd)
 }
+
+// defaultCellPixelWidth is the assumed width of a terminal cell in pixels
+// when the terminal does not report its pixel size
+const defaultCellPixelWidth = 8
+
+// TerminalPixelWidth returns the approximate pixel width of the given number
+// of terminal columns, using the cell size reported by the terminal if any
+func TerminalPixelWidth(columns int) int {
+	cell := terminalCellPixelWidth()
+	if cell <= 0 {
+		cell = defaultCellPixelWidth
+	}
+	return columns * cell
+}
+
+// hyperlinkTerminals are the TERM_PROGRAM values of terminals that support
+// OSC 8 hyperlinks
+var hyperlinkTerminals = map[string]bool{
+	"iTerm.app":    true,
+	"WezTerm":      true,
+	"vscode":       true,
+	"ghostty":      true,
+	"WarpTerminal": true,
+	"Hyper":        true,
+	"Tabby":        true,
+	"rio":          true,
+}
+
+// SupportsHyperlinks reports whether stdout is a terminal that shows OSC 8
+// hyperlinks. FORCE_HYPERLINK=1 (or 0) overrides the detection.
+func SupportsHyperlinks() bool {
+	if force, ok := os.LookupEnv("FORCE_HYPERLINK"); ok {
+		return force != "0" && force != ""
+	}
+	if !IsTerminal() {
+		return false
+	}
+
+	term := os.Getenv("TERM")
+	if term == "dumb" {
+		return false
+	}
+	if hyperlinkTerminals[os.Getenv("TERM_PROGRAM")] {
+		return true
+	}
+	for _, name := range []string{"kitty", "alacritty", "foot", "ghostty", "wezterm"} {
+		if strings.Contains(term, name) {
+			return true
+		}
+	}
+	for _, env := range []string{"KITTY_WINDOW_ID", "WT_SESSION", "KONSOLE_VERSION", "DOMTERM"} {
+		if os.Getenv(env) != "" {
+			return true
+		}
+	}
+	// GNOME Terminal, Tilix and other VTE terminals since VTE 0.50
+	if vte, err := strconv.Atoi(os.Getenv("VTE_VERSION")); err == nil && vte >= 5000 {
+		return true
+	}
+	return false
+}
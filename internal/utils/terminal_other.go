@@ -0,0 +1,9 @@
+//go:build !unix
+
+package utils
+
+// terminalCellPixelWidth returns 0 as the pixel size is not available on
+// this platform
+func terminalCellPixelWidth() int {
+	return 0
+}
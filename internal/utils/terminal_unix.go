@@ -0,0 +1,19 @@
+//go:build unix
+
+package utils
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// terminalCellPixelWidth returns the width of one terminal cell in pixels,
+// or 0 if the terminal does not report its pixel size
+func terminalCellPixelWidth() int {
+	ws, err := unix.IoctlGetWinsize(int(os.Stdout.Fd()), unix.TIOCGWINSZ)
+	if err != nil || ws.Col == 0 || ws.Xpixel == 0 {
+		return 0
+	}
+	return int(ws.Xpixel) / int(ws.Col)
+}
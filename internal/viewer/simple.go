@@ -2,6 +2,7 @@ package viewer
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
@@ -14,7 +15,8 @@ import (
 // SimpleViewer displays markdown content directly to stdout
 type SimpleViewer struct {
 	renderer *renderer.Renderer
-	basePath string // Base directory for resolving relative image paths
+	basePath string                    // Base directory for resolving relative image paths
+	fetcher  *utils.RemoteImageFetcher // Created on first remote image
 }
 
 // NewSimpleViewer creates a new simple viewer
@@ -34,9 +36,23 @@ func (v *SimpleViewer) SetBasePath(path string) {
 func (v *SimpleViewer) View(content []byte) error {
 	opts := v.renderer.GetOptions()
 
+	// Show the frontmatter as a metadata table instead of markdown
+	body := v.renderer.StripFrontmatter(string(content))
+	if opts.Section != "" {
+		// Only the requested section, without the metadata table
+		section, err := renderer.FindSection(string(content), opts.Section)
+		if err != nil {
+			return err
+		}
+		body = section
+	} else if table := v.renderer.RenderFrontmatter(); table != "" {
+		fmt.Print(table)
+	}
+	body = v.renderer.InsertTOC(body)
+
 	// Always use inline content rendering to handle both images and mermaid.
 	// The function will detect if there are any special blocks to handle.
-	if err := v.renderWithInlineContent(content, opts); err != nil {
+	if err := v.renderWithInlineContent([]byte(body), opts); err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: inline content rendering failed: %v\n", err)
 	}
 
@@ -50,14 +66,39 @@ func (v *SimpleViewer) ViewFile(path string) error {
 		v.basePath = filepath.Dir(absPath)
 	}
 
+	// Let the renderer resolve wiki-links relative to this file (and its
+	// Obsidian vault, if any)
+	if err := v.renderer.SetSourcePath(path); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to index Obsidian vault: %v\n", err)
+	}
+
 	content, err := utils.ReadFile(path)
 	if err != nil {
 		return err
 	}
 
+	if utils.IsCanvasFile(path) {
+		return v.ViewCanvas(content)
+	}
+
 	return v.View(content)
 }
 
+// ViewCanvas renders and displays an Obsidian canvas
+func (v *SimpleViewer) ViewCanvas(content []byte) error {
+	canvas, err := renderer.ParseCanvas(content)
+	if err != nil {
+		return err
+	}
+
+	rendered, err := v.renderer.RenderCanvas(canvas)
+	if err != nil {
+		return fmt.Errorf("failed to render canvas: %w", err)
+	}
+	fmt.Print(rendered)
+	return nil
+}
+
 // ViewStdin reads from stdin and displays the content
 func (v *SimpleViewer) ViewStdin() error {
 	content, err := utils.ReadFile("-")
@@ -73,7 +114,7 @@ func (v *SimpleViewer) ViewStdin() error {
 func (v *SimpleViewer) renderWithInlineContent(content []byte, opts renderer.RenderOptions) error {
 	// First preprocess links so we detect images after Obsidian syntax conversion
 	text := v.renderer.PreprocessLinks(string(content))
-	blocks := renderer.DetectContentBlocks(text)
+	blocks := v.renderer.DetectContentBlocks(text)
 	if len(blocks) == 0 {
 		// No special content: just render normally with preprocessed content.
 		rendered, err := v.renderer.RenderBytes([]byte(text))
@@ -86,17 +127,17 @@ func (v *SimpleViewer) renderWithInlineContent(content []byte, opts renderer.Ren
 
 	lines := strings.Split(text, "\n")
 
-	// Only create mermaid compiler if we have mermaid blocks
+	// Only create the headless Chrome compiler if we have mermaid blocks
 	var compiler *mermaid.Compiler
-	hasMermaid := false
+	needsCompiler := false
 	for _, block := range blocks {
 		if block.Type == renderer.BlockTypeMermaid {
-			hasMermaid = true
+			needsCompiler = true
 			break
 		}
 	}
 
-	if hasMermaid {
+	if needsCompiler {
 		var err error
 		compiler, err = mermaid.NewCompiler()
 		if err != nil {
@@ -109,6 +150,7 @@ func (v *SimpleViewer) renderWithInlineContent(content []byte, opts renderer.Ren
 			return fmt.Errorf("failed to create mermaid compiler: %w", err)
 		}
 		defer compiler.Close()
+		compiler.SetTheme(opts.MermaidTheme)
 	}
 
 	currLine := 0
@@ -168,6 +210,13 @@ func (v *SimpleViewer) renderSingleContentBlock(compiler *mermaid.Compiler, bloc
 		return v.renderSingleMermaidBlock(compiler, *block.Mermaid, index, opts)
 	case renderer.BlockTypeImage:
 		return v.renderSingleImageBlock(*block.Image, index)
+	case renderer.BlockTypeCallout:
+		rendered, err := v.renderer.RenderCallout(*block.Callout)
+		if err != nil {
+			return err
+		}
+		fmt.Print(rendered)
+		return nil
 	default:
 		return fmt.Errorf("unknown block type: %v", block.Type)
 	}
@@ -224,7 +273,7 @@ func (v *SimpleViewer) renderSingleMermaidBlock(compiler *mermaid.Compiler, bloc
 			if err := mermaid.SaveSVGToFile(result.SVG, outputPath); err != nil {
 				fmt.Fprintf(os.Stderr, "Warning: failed to save SVG: %v\n", err)
 			} else {
-				fmt.Printf("  💾 Saved to: %s\n", outputPath)
+				fmt.Printf("  💾 Saved to: %s\n", v.renderer.FileLink(outputPath))
 			}
 		}
 
@@ -241,7 +290,7 @@ func (v *SimpleViewer) renderSingleMermaidBlock(compiler *mermaid.Compiler, bloc
 		} else {
 			fmt.Print(code)
 		}
-		fmt.Printf("📁 Mermaid diagram %d %s\n", index+1, outputPath)
+		fmt.Printf("📁 Mermaid diagram %d %s\n", index+1, v.renderer.FileLink(outputPath))
 
 	case "png":
 		width := result.Width
@@ -269,7 +318,7 @@ func (v *SimpleViewer) renderSingleMermaidBlock(compiler *mermaid.Compiler, bloc
 		} else {
 			fmt.Print(code)
 		}
-		fmt.Printf("📁 Mermaid diagram %d %s\n", index+1, outputPath)
+		fmt.Printf("📁 Mermaid diagram %d %s\n", index+1, v.renderer.FileLink(outputPath))
 	}
 
 	return nil
@@ -280,37 +329,62 @@ func (v *SimpleViewer) renderSingleImageBlock(block renderer.ImageBlock, index i
 	// Check if terminal supports inline images
 	if !utils.SupportsInlineImages() {
 		// Fallback: let Glamour render the image as text
-		markdownImg := fmt.Sprintf("![%s](%s)", block.AltText, block.Path)
-		rendered, err := v.renderer.RenderBytes([]byte(markdownImg))
-		if err != nil {
-			return fmt.Errorf("failed to render image placeholder: %w", err)
+		return v.renderImagePlaceholder(block)
+	}
+
+	// Load image data from disk or the remote image cache
+	imageData, err := v.loadImage(block)
+	if err != nil {
+		if block.Remote {
+			fmt.Fprintf(os.Stderr, "Warning: failed to fetch remote image: %v\n", err)
 		}
-		fmt.Print(rendered)
-		return nil
+		// File not found or error reading - fall back to text representation
+		return v.renderImagePlaceholder(block)
 	}
 
-	// Resolve image path
-	imgPath := block.Path
-	if !filepath.IsAbs(imgPath) {
-		imgPath = filepath.Join(v.basePath, imgPath)
+	caption := block.AltText
+	if caption == "" {
+		caption = "Image"
+	}
+	frames := utils.GIFFrameCount(imageData)
+	if frames > 1 {
+		caption = fmt.Sprintf("%s (%d frames)", caption, frames)
 	}
 
-	// Load image file
-	imageData, err := os.ReadFile(imgPath)
-	if err != nil {
-		// File not found or error reading - fall back to text representation
-		markdownImg := fmt.Sprintf("![%s](%s)", block.AltText, block.Path)
-		rendered, err := v.renderer.RenderBytes([]byte(markdownImg))
-		if err != nil {
-			return fmt.Errorf("failed to render image placeholder: %w", err)
+	// Animated GIFs play as a Kitty animation, and natively in terminals
+	// using the iTerm2 protocol. With --no-animate only the first frame is
+	// sent, whatever the protocol.
+	protocol := utils.DetectImageProtocol()
+	width, height := v.imageTargetSize(block)
+	if frames > 1 && v.renderer.GetOptions().NoAnimate {
+		if first, err := utils.GIFFirstFrame(imageData); err == nil {
+			imageData = first
 		}
-		fmt.Print(rendered)
+	} else if frames > 1 && protocol == utils.ProtocolKitty {
+		fmt.Printf("🖼️  %s:\n", caption)
+		if err := v.displayKittyAnimation(imageData, width, height); err != nil {
+			return fmt.Errorf("failed to display animation: %w", err)
+		}
+		fmt.Println()
 		return nil
 	}
 
-	// Resize image if width specified
-	if block.Width > 0 {
-		resized, err := utils.ResizeImage(imageData, block.Width)
+	// SVG images are rasterized by headless Chrome at the requested width.
+	// If Chrome is unavailable, fall back to the text representation.
+	if renderer.IsSVGImage(block.Path) || (block.Remote && utils.IsSVG(imageData)) {
+		pngData, err := utils.RasterizeSVG(imageData, width, height)
+		if err != nil {
+			return v.renderImagePlaceholder(block)
+		}
+		imageData = pngData
+	} else if width > 0 || height > 0 {
+		// Resize image if a size was specified
+		filter, _ := utils.ParseResampleFilter(v.renderer.GetOptions().ImageResampling)
+		resized, err := utils.ResizeImageWithOptions(imageData, utils.ResizeOptions{
+			Width:  width,
+			Height: height,
+			Filter: filter,
+		})
 		if err != nil {
 			// If resize fails, use original
 			fmt.Fprintf(os.Stderr, "Warning: failed to resize image: %v\n", err)
@@ -320,13 +394,8 @@ func (v *SimpleViewer) renderSingleImageBlock(block renderer.ImageBlock, index i
 	}
 
 	// Display inline image
-	if block.AltText != "" {
-		fmt.Printf("🖼️  %s:\n", block.AltText)
-	} else {
-		fmt.Printf("🖼️  Image:\n")
-	}
+	fmt.Printf("🖼️  %s:\n", caption)
 
-	protocol := utils.DetectImageProtocol()
 	if err := utils.DisplayInlineImage(imageData, protocol); err != nil {
 		return fmt.Errorf("failed to display inline image: %w", err)
 	}
@@ -334,3 +403,72 @@ func (v *SimpleViewer) renderSingleImageBlock(block renderer.ImageBlock, index i
 
 	return nil
 }
+
+// displayKittyAnimation plays an animated GIF with Kitty's animation
+// protocol, scaling its frames to the requested size
+func (v *SimpleViewer) displayKittyAnimation(imageData []byte, width, height int) error {
+	frames, delays, err := utils.GIFFrames(imageData)
+	if err != nil {
+		return err
+	}
+	if width > 0 || height > 0 {
+		filter, _ := utils.ParseResampleFilter(v.renderer.GetOptions().ImageResampling)
+		for i, frame := range frames {
+			frames[i] = utils.ScaleImage(frame, utils.ResizeOptions{Width: width, Height: height, Filter: filter})
+		}
+	}
+	return utils.DisplayKittyAnimation(frames, delays)
+}
+
+// imageTargetSize returns the requested pixel size of an image block,
+// resolving percentage widths against the terminal width
+func (v *SimpleViewer) imageTargetSize(block renderer.ImageBlock) (int, int) {
+	if block.Percent > 0 {
+		columns := v.renderer.GetOptions().Width
+		return utils.TerminalPixelWidth(columns) * block.Percent / 100, 0
+	}
+	return block.Width, block.Height
+}
+
+// renderImagePlaceholder lets Glamour render an image as text when it cannot
+// be displayed inline
+func (v *SimpleViewer) renderImagePlaceholder(block renderer.ImageBlock) error {
+	markdownImg := fmt.Sprintf("![%s](%s)", block.AltText, block.Path)
+	rendered, err := v.renderer.RenderBytes([]byte(markdownImg))
+	if err != nil {
+		return fmt.Errorf("failed to render image placeholder: %w", err)
+	}
+	fmt.Print(rendered)
+	return nil
+}
+
+// loadImage returns the raw bytes of an image block. Local paths are resolved
+// against the base path; remote URLs go through the remote image fetcher.
+func (v *SimpleViewer) loadImage(block renderer.ImageBlock) ([]byte, error) {
+	if block.Remote {
+		if v.fetcher == nil {
+			opts := v.renderer.GetOptions()
+			v.fetcher = utils.NewRemoteImageFetcher(utils.DefaultImageCacheDir())
+			v.fetcher.SetOffline(opts.Offline)
+			if opts.RemoteTimeout > 0 {
+				v.fetcher.SetTimeout(opts.RemoteTimeout)
+			}
+			if opts.RemoteMaxBytes > 0 {
+				v.fetcher.SetMaxBytes(opts.RemoteMaxBytes)
+			}
+		}
+		return v.fetcher.Fetch(block.Path)
+	}
+
+	imgPath := block.Path
+	if !filepath.IsAbs(imgPath) {
+		imgPath = filepath.Join(v.basePath, imgPath)
+	}
+	// Paths may be URL-escaped, as in ![img](my%20image.png)
+	if _, err := os.Stat(imgPath); err != nil {
+		if unescaped, uErr := url.PathUnescape(imgPath); uErr == nil {
+			imgPath = unescaped
+		}
+	}
+	return os.ReadFile(imgPath)
+}